@@ -1,18 +1,26 @@
 package apis
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/labstack/echo/v5"
 	"github.com/pedrozadotdev/pocketblocks/server/daos"
+	"github.com/pedrozadotdev/pocketblocks/server/dslcheck"
+	"github.com/pedrozadotdev/pocketblocks/server/jsonpatch"
+	"github.com/pedrozadotdev/pocketblocks/server/llm"
+	"github.com/pedrozadotdev/pocketblocks/server/models"
 	"github.com/pocketbase/pocketbase"
 )
 
@@ -36,6 +44,10 @@ func BindAiApi(app *pocketbase.PocketBase, dao *daos.Dao, ob *openblocksApi, e *
 	e.GET("/api/ai/config", api.getConfig)
 	e.PUT("/api/ai/config", api.setConfig)
 	e.POST("/api/ai/chat", api.chat)
+	e.POST("/api/ai/chat/stream", api.chatStream)
+	e.GET("/api/ai/threads", api.threadsList)
+	e.GET("/api/ai/threads/:id", api.threadView)
+	e.DELETE("/api/ai/threads/:id", api.threadDelete)
 	e.POST("/api/ai/auth/save-tokens", api.saveTokens)
 	e.POST("/api/ai/auth/codex-import", api.importCodexAuth)
 }
@@ -71,6 +83,7 @@ func (api *aiApi) getConfig(c echo.Context) error {
 	}
 
 	auth := api.getStoredAuth()
+	config := api.getProviderConfig()
 	codexAvailable := api.codexAuthFileExists()
 	isAdm := api.ob.isAdmin(c)
 
@@ -80,7 +93,11 @@ func (api *aiApi) getConfig(c echo.Context) error {
 		"authMethod":     auth.AuthMethod,
 		"codexAvailable": codexAvailable,
 		"isAdmin":        isAdm,
-		"model":          "gpt-4o",
+		"provider":       config.ProviderName(),
+		"providers":      llm.Providers,
+		"model":          config.ModelName(),
+		"baseUrl":        config.BaseUrl,
+		"temperature":    config.TemperatureValue(),
 	})
 }
 
@@ -90,13 +107,41 @@ func (api *aiApi) setConfig(c echo.Context) error {
 	}
 
 	var body struct {
-		ApiKey string `json:"apiKey"`
-		Clear  bool   `json:"clear"`
+		ApiKey      string   `json:"apiKey"`
+		Clear       bool     `json:"clear"`
+		Provider    *string  `json:"provider"`
+		Model       *string  `json:"model"`
+		BaseUrl     *string  `json:"baseUrl"`
+		Temperature *float64 `json:"temperature"`
 	}
 	if err := c.Bind(&body); err != nil {
 		return errResp(c, 400, "Invalid request")
 	}
 
+	// only the sent provider options are changed
+	if body.Provider != nil || body.Model != nil || body.BaseUrl != nil || body.Temperature != nil {
+		config := api.getProviderConfig()
+		if body.Provider != nil {
+			config.Provider = *body.Provider
+		}
+		if body.Model != nil {
+			config.Model = *body.Model
+		}
+		if body.BaseUrl != nil {
+			config.BaseUrl = *body.BaseUrl
+		}
+		if body.Temperature != nil {
+			config.Temperature = body.Temperature
+		}
+
+		if err := config.Validate(); err != nil {
+			return errResp(c, 400, err.Error())
+		}
+		if err := api.saveProviderConfig(config); err != nil {
+			return errResp(c, 500, "Failed to store the AI provider config")
+		}
+	}
+
 	if body.Clear {
 		if err := api.saveAuth(storedAuth{}); err != nil {
 			return errResp(c, 500, "Failed to clear auth")
@@ -208,6 +253,24 @@ func (api *aiApi) saveAuth(auth storedAuth) error {
 	return api.dao.SaveParam("pbl_ai_auth", auth)
 }
 
+// getProviderConfig returns the admin configured AI provider options
+// (stored next to the "pbl_ai_auth" credentials).
+func (api *aiApi) getProviderConfig() llm.Config {
+	var config llm.Config
+
+	param, err := api.dao.FindParamByKey("pbl_ai_provider")
+	if err != nil {
+		return config
+	}
+	json.Unmarshal(param.Value, &config)
+
+	return config
+}
+
+func (api *aiApi) saveProviderConfig(config llm.Config) error {
+	return api.dao.SaveParam("pbl_ai_provider", config)
+}
+
 func (api *aiApi) getStoredAPIKeyLegacy() string {
 	param, err := api.dao.FindParamByKey("pbl_openai_key")
 	if err != nil {
@@ -285,51 +348,72 @@ func (api *aiApi) refreshAccessToken(refreshToken string) (string, string, error
 	return tokenResp.AccessToken, newRefresh, nil
 }
 
-// --- Resolve the bearer token for OpenAI API calls ---
+// --- Provider calls ---
 
-func (api *aiApi) resolveOpenAIAuth() (string, error) {
-	auth := api.getStoredAuth()
-
-	if auth.AuthMethod == "api_key" && auth.APIKey != "" {
-		return auth.APIKey, nil
-	}
-
-	if auth.AuthMethod == "codex_chatgpt" && auth.AccessToken != "" {
-		return auth.AccessToken, nil
-	}
-
-	return "", fmt.Errorf("no AI authentication configured")
-}
-
-func (api *aiApi) handleAuthFailureAndRetry(reqFn func(token string) (*http.Response, error)) (*http.Response, error) {
+// withProvider calls fn with the configured provider.
+//
+// An expired ChatGPT/Codex access token is refreshed (and stored)
+// and fn called once again with the new one.
+func (api *aiApi) withProvider(fn func(provider llm.Provider) (*llm.Response, error)) (*llm.Response, error) {
+	config := api.getProviderConfig()
 	auth := api.getStoredAuth()
 
 	token := auth.APIKey
 	if auth.AuthMethod == "codex_chatgpt" {
 		token = auth.AccessToken
 	}
-	if token == "" {
-		return nil, fmt.Errorf("no AI authentication configured")
-	}
 
-	resp, err := reqFn(token)
+	provider, err := llm.New(config, token)
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.StatusCode == 401 && auth.AuthMethod == "codex_chatgpt" && auth.RefreshToken != "" {
-		resp.Body.Close()
-		newAccess, newRefresh, err := api.refreshAccessToken(auth.RefreshToken)
-		if err != nil {
-			return nil, fmt.Errorf("token refresh failed: %w", err)
-		}
-		auth.AccessToken = newAccess
-		auth.RefreshToken = newRefresh
-		api.saveAuth(auth)
-		return reqFn(newAccess)
+	resp, err := fn(provider)
+	if !llm.IsUnauthorized(err) || auth.AuthMethod != "codex_chatgpt" || auth.RefreshToken == "" {
+		return resp, err
+	}
+
+	newAccess, newRefresh, err := api.refreshAccessToken(auth.RefreshToken)
+	if err != nil {
+		return nil, fmt.Errorf("token refresh failed: %w", err)
+	}
+	auth.AccessToken = newAccess
+	auth.RefreshToken = newRefresh
+	api.saveAuth(auth)
+
+	if provider, err = llm.New(config, newAccess); err != nil {
+		return nil, err
 	}
 
-	return resp, nil
+	return fn(provider)
+}
+
+// complete sends the request to the configured provider.
+func (api *aiApi) complete(ctx context.Context, req *llm.Request) (*llm.Response, error) {
+	return api.withProvider(func(provider llm.Provider) (*llm.Response, error) {
+		return provider.Chat(ctx, req)
+	})
+}
+
+// completeStream sends the request to the configured provider
+// streaming the generated chunks to onDelta.
+func (api *aiApi) completeStream(ctx context.Context, req *llm.Request, onDelta llm.DeltaFunc) (*llm.Response, error) {
+	return api.withProvider(func(provider llm.Provider) (*llm.Response, error) {
+		return provider.ChatStream(ctx, req, onDelta)
+	})
+}
+
+// aiErrorMessage returns the status and message reported for a failed AI request.
+//
+// The provider errors are reported as 502 instead of forwarding the provider
+// status code directly, because a 401 from the provider would trigger
+// the client's auth interceptor and log the user out.
+func aiErrorMessage(err error) (int, string) {
+	var statusErr *llm.StatusError
+	if errors.As(err, &statusErr) {
+		return 502, "AI service error: " + statusErr.Body
+	}
+	return 500, "AI request failed: " + err.Error()
 }
 
 // --- Chat endpoint ---
@@ -355,6 +439,10 @@ Each component in "comp" has:
 - "comp": component-specific properties
 - "name": display name
 
+The pages saved by the editor use "items" instead of "comp", with the components
+keyed by a unique id and named by their "name" (the "layout" entries use the same ids).
+When modifying an existing DSL, keep its format.
+
 ## Layout System
 Components use a grid layout (24 columns wide). Each component has layout info:
 - "i": component key (matches the key in comp)
@@ -431,37 +519,146 @@ JavaScript queries fetch/process data:
 "queries": {"query1": {"compType": "js", "comp": {"script": "return fetch('/api/data').then(r => r.json())"}}}
 
 ## Rules
-1. ALWAYS return valid JSON for the complete DSL
+1. ALWAYS return valid JSON
 2. Use unique component names (e.g., "text1", "button1", "table1")
 3. Position components using the 24-column grid
 4. Keep the layout clean and well-organized
 5. Use meaningful default values for components
-6. When modifying existing DSL, preserve components that shouldn't change
+6. When modifying existing DSL, preserve components that shouldn't change`
 
-## Response Format
+// dslResponseFormat is the reply format of the "dsl" chat mode.
+const dslResponseFormat = `## Response Format
 You MUST respond with ONLY a JSON object with two keys:
 - "explanation": Brief text explaining what you did
 - "dsl": The complete page DSL JSON object
 
 Do NOT include markdown code fences, explanatory text outside the JSON, or anything else. Return ONLY the JSON object.`
 
-func (api *aiApi) chat(c echo.Context) error {
-	if !api.ob.isLoggedIn(c) {
-		return errResp(c, 401, "Unauthorized")
+// patchResponseFormat is the reply format of the "patch" chat mode.
+const patchResponseFormat = `## Response Format
+You MUST respond with ONLY a JSON object with two keys:
+- "explanation": Brief text explaining what you did
+- "patch": A JSON Patch (RFC 6902) array with the operations to apply to the current page DSL
+
+Each patch operation has:
+- "op": "add", "remove", "replace", "move", "copy" or "test"
+- "path": JSON pointer of the changed location (e.g., "/ui/comp/button1", "/ui/layout/button1"), with "~1" for "/" and "~0" for "~" in keys
+- "value": the new value (for add, replace and test)
+- "from": JSON pointer of the source location (for move and copy)
+
+The patch is always applied to the current page DSL of the user request.
+Only include the operations needed for the requested change, never rewrite the whole DSL
+and only change the paths allowed in the user request.
+
+Do NOT include markdown code fences, explanatory text outside the JSON, or anything else. Return ONLY the JSON object.`
+
+const (
+	chatModeDsl   = "dsl"
+	chatModePatch = "patch"
+)
+
+// chatBody is the request body of the chat endpoints.
+//
+// The chats with an app (slug) are recorded in a new thread
+// or in the provided one.
+type chatBody struct {
+	Message    string      `json:"message"`
+	CurrentDSL interface{} `json:"currentDSL"`
+	AppId      string      `json:"appId"`
+	ThreadId   string      `json:"threadId"`
+
+	// Mode is the reply mode: "dsl" (the complete page DSL, default)
+	// or "patch" (RFC 6902 operations applied to CurrentDSL).
+	Mode string `json:"mode"`
+
+	// Scope lists the JSON pointers a patch is allowed to change.
+	Scope []string `json:"scope"`
+
+	// SelectedComp is the name of the component selected in the editor,
+	// the only one (with its layout entry) a patch can change if there is no Scope.
+	SelectedComp string `json:"selectedComp"`
+}
+
+func (body *chatBody) validate() error {
+	if body.Message == "" {
+		return errors.New("Message is required")
 	}
 
-	var body struct {
-		Message    string      `json:"message"`
-		CurrentDSL interface{} `json:"currentDSL"`
+	switch body.Mode {
+	case "", chatModeDsl:
+	case chatModePatch:
+		if body.CurrentDSL == nil {
+			return errors.New("The current DSL is required in patch mode")
+		}
+	default:
+		return errors.New("Invalid mode")
 	}
-	if err := c.Bind(&body); err != nil {
-		return errResp(c, 400, "Invalid request")
+
+	for _, pointer := range body.Scope {
+		if _, err := jsonpatch.ParsePointer(pointer); err != nil || pointer == "" {
+			return errors.New("Invalid scope")
+		}
 	}
 
-	if body.Message == "" {
-		return errResp(c, 400, "Message is required")
+	if body.Mode == chatModePatch && len(body.patchScope()) == 0 {
+		return errors.New("The scope or a selected component of the current DSL is required in patch mode")
+	}
+
+	return nil
+}
+
+// patchScope returns the JSON pointers a patch is allowed to change
+// (the Scope or the SelectedComp ones).
+func (body *chatBody) patchScope() []string {
+	if len(body.Scope) > 0 {
+		return body.Scope
+	}
+	if body.SelectedComp == "" {
+		return nil
+	}
+	return componentPointers(body.CurrentDSL, body.SelectedComp, nil)
+}
+
+// componentPointers finds the named component in the dsl node and returns
+// the pointers of the component and of its layout entry.
+//
+// The components of the editor containers ("items") are keyed by id
+// and matched by their "name", the other ones ("comp") by their key.
+func componentPointers(node any, name string, tokens []string) []string {
+	switch v := node.(type) {
+	case map[string]any:
+		for _, compsKey := range []string{"items", "comp"} {
+			comps, _ := v[compsKey].(map[string]any)
+			for _, key := range slices.Sorted(maps.Keys(comps)) {
+				comp, ok := comps[key].(map[string]any)
+				if !ok || comp["compType"] == nil {
+					continue
+				}
+				if n, _ := comp["name"].(string); (compsKey == "items" && n == name) || (compsKey == "comp" && key == name) {
+					return []string{
+						jsonpatch.Pointer(append(slices.Clone(tokens), compsKey, key)...),
+						jsonpatch.Pointer(append(slices.Clone(tokens), "layout", key)...),
+					}
+				}
+			}
+		}
+		for _, key := range slices.Sorted(maps.Keys(v)) {
+			if result := componentPointers(v[key], name, append(slices.Clone(tokens), key)); result != nil {
+				return result
+			}
+		}
+	case []any:
+		for i, child := range v {
+			if result := componentPointers(child, name, append(slices.Clone(tokens), strconv.Itoa(i))); result != nil {
+				return result
+			}
+		}
 	}
 
+	return nil
+}
+
+func newChatRequest(body *chatBody, thread *models.AiThread) *llm.Request {
 	currentDSLJSON := "{}"
 	if body.CurrentDSL != nil {
 		b, _ := json.Marshal(body.CurrentDSL)
@@ -473,77 +670,466 @@ func (api *aiApi) chat(c echo.Context) error {
 		userMessage = fmt.Sprintf("Current page DSL:\n```json\n%s\n```\n\nUser request: %s", currentDSLJSON, body.Message)
 	}
 
-	openaiReq := map[string]interface{}{
-		"model": "gpt-4o",
-		"messages": []map[string]interface{}{
-			{"role": "system", "content": systemPrompt},
-			{"role": "user", "content": userMessage},
-		},
-		"temperature":    0.7,
-		"max_tokens":     16000,
-		"response_format": map[string]interface{}{"type": "json_object"},
+	responseFormat := dslResponseFormat
+	if body.Mode == chatModePatch {
+		responseFormat = patchResponseFormat
+		userMessage += fmt.Sprintf("\n\nAllowed patch paths (and their children): %s", strings.Join(body.patchScope(), ", "))
+	}
+
+	messages := []llm.Message{{Role: llm.RoleSystem, Content: systemPrompt + "\n\n" + responseFormat}}
+	if thread != nil {
+		history := thread.Messages[max(0, len(thread.Messages)-maxThreadHistory):]
+		for _, m := range history {
+			messages = append(messages, llm.Message{Role: m.Role, Content: m.Content})
+		}
+	}
+	messages = append(messages, llm.Message{Role: llm.RoleUser, Content: userMessage})
+
+	return &llm.Request{
+		Messages: messages,
+		JSON:     true,
+	}
+}
+
+// parseChatResult parses the generated explanation/dsl object
+// (the raw content is returned as explanation if it isn't valid JSON).
+func parseChatResult(content string) map[string]interface{} {
+	var aiResult map[string]interface{}
+	if err := json.Unmarshal([]byte(llm.ExtractJSON(content)), &aiResult); err != nil {
+		return map[string]interface{}{
+			"explanation": content,
+			"dsl":         nil,
+			"raw":         content,
+		}
+	}
+	return aiResult
+}
+
+func (api *aiApi) chat(c echo.Context) error {
+	if !api.ob.isLoggedIn(c) {
+		return errResp(c, 401, "Unauthorized")
+	}
+
+	body := &chatBody{}
+	if err := c.Bind(body); err != nil {
+		return errResp(c, 400, "Invalid request")
+	}
+
+	if err := body.validate(); err != nil {
+		return errResp(c, 400, err.Error())
+	}
+
+	thread, err := api.chatThread(c, body)
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+
+	result, err := generate(newChatRequest(body, thread), body.checkReply, func(req *llm.Request) (*llm.Response, error) {
+		return api.complete(ctx, req)
+	}, nil)
+	if err != nil {
+		status, message := aiErrorMessage(err)
+		return errResp(c, status, message)
+	}
+
+	api.recordChat(thread, body, result)
+	return okResp(c, result)
+}
+
+// chatStream is the streaming variant of chat.
+//
+// The generated chunks are relayed as "delta" server-sent events and
+// the parsed reply is sent as a final "done" event (or an
+// "error" one). Closing the connection cancels the provider request.
+//
+// An invalid reply is reported with a "validation" event before
+// the deltas of the repair attempt (so the previous ones should be discarded).
+func (api *aiApi) chatStream(c echo.Context) error {
+	if !api.ob.isLoggedIn(c) {
+		return errResp(c, 401, "Unauthorized")
+	}
+
+	body := &chatBody{}
+	if err := c.Bind(body); err != nil {
+		return errResp(c, 400, "Invalid request")
+	}
+
+	if err := body.validate(); err != nil {
+		return errResp(c, 400, err.Error())
+	}
+
+	thread, err := api.chatThread(c, body)
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+
+	w := c.Response()
+	w.Header().Set(echo.HeaderContentType, "text/event-stream")
+	w.Header().Set(echo.HeaderCacheControl, "no-cache")
+	w.Header().Set(echo.HeaderConnection, "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	w.Flush()
+
+	onDelta := func(delta string) error {
+		return writeSSE(w, "delta", map[string]interface{}{"content": delta})
+	}
+	onRepair := func(attempt int, report *dslcheck.Report) error {
+		return writeSSE(w, "validation", map[string]interface{}{"attempt": attempt, "validation": report})
+	}
+
+	result, err := generate(newChatRequest(body, thread), body.checkReply, func(req *llm.Request) (*llm.Response, error) {
+		return api.completeStream(ctx, req, onDelta)
+	}, onRepair)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil // cancelled by the client
+		}
+		status, message := aiErrorMessage(err)
+		return writeSSE(w, "error", map[string]interface{}{"code": status, "message": message})
 	}
 
-	reqBody, err := json.Marshal(openaiReq)
+	api.recordChat(thread, body, result)
+	return writeSSE(w, "done", result)
+}
+
+// writeSSE writes and flushes a single server-sent event with JSON data.
+func writeSSE(w *echo.Response, event string, data interface{}) error {
+	raw, err := json.Marshal(data)
 	if err != nil {
-		return errResp(c, 500, "Failed to build AI request")
+		return err
+	}
+
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, raw); err != nil {
+		return err
 	}
+	w.Flush()
 
-	resp, err := api.handleAuthFailureAndRetry(func(token string) (*http.Response, error) {
-		req, err := http.NewRequest("POST", "https://api.openai.com/v1/chat/completions", bytes.NewReader(reqBody))
+	return nil
+}
+
+// --- DSL validation ---
+
+// componentCatalog is the set of the component types listed in the system prompt.
+var componentCatalog = dslcheck.ParseCatalog(systemPrompt)
+
+// maxRepairAttempts is the max number of times the model is asked
+// to fix the errors of a reply.
+const maxRepairAttempts = 2
+
+// generate runs the chat request with call and checks the reply,
+// re-prompting the model with the validation errors (up to maxRepairAttempts times).
+//
+// The returned result has the validation report of the last reply
+// and the number of attempts. onRepair (if set) is called with
+// the failed report before every repair attempt.
+func generate(
+	req *llm.Request,
+	check func(content string) (map[string]interface{}, *dslcheck.Report),
+	call func(req *llm.Request) (*llm.Response, error),
+	onRepair func(attempt int, report *dslcheck.Report) error,
+) (map[string]interface{}, error) {
+	for attempt := 1; ; attempt++ {
+		resp, err := call(req)
 		if err != nil {
 			return nil, err
 		}
-		req.Header.Set("Content-Type", "application/json")
-		if strings.HasPrefix(token, "sk-") {
-			req.Header.Set("Authorization", "Bearer "+token)
-		} else {
-			req.Header.Set("Authorization", "Bearer "+token)
+
+		result, report := check(resp.Content)
+		if report.Valid || attempt > maxRepairAttempts {
+			result["validation"] = report
+			result["attempts"] = attempt
+			return result, nil
 		}
-		return (&http.Client{}).Do(req)
-	})
+
+		if onRepair != nil {
+			if err := onRepair(attempt, report); err != nil {
+				return nil, err
+			}
+		}
+
+		req.Messages = append(req.Messages,
+			llm.Message{Role: llm.RoleAssistant, Content: resp.Content},
+			llm.Message{Role: llm.RoleUser, Content: fmt.Sprintf(
+				"The previous reply is invalid:\n%s\nFix these errors and respond again with the complete JSON object in the same response format.",
+				report.Prompt(),
+			)},
+		)
+	}
+}
+
+// checkReply parses and validates a raw model reply of the chat mode.
+func (body *chatBody) checkReply(content string) (map[string]interface{}, *dslcheck.Report) {
+	if body.Mode == chatModePatch {
+		return body.checkPatchReply(content)
+	}
+
+	return parseChatResult(content), checkDslReply(content)
+}
+
+// checkDslReply validates the dsl of a raw explanation/dsl reply.
+func checkDslReply(content string) *dslcheck.Report {
+	reply := struct {
+		Dsl json.RawMessage `json:"dsl"`
+	}{}
+	if err := json.Unmarshal([]byte(llm.ExtractJSON(content)), &reply); err != nil {
+		return invalidReply(&dslcheck.Issue{Message: "the reply must be a JSON object with the explanation and dsl keys"})
+	}
+
+	return dslcheck.Validate(reply.Dsl, componentCatalog)
+}
+
+// checkPatchReply applies the patch of a raw explanation/patch reply
+// to the current dsl and validates the changes of the resulting one.
+//
+// The patches changing paths outside of the chat scope (see patchScope) are rejected.
+func (body *chatBody) checkPatchReply(content string) (map[string]interface{}, *dslcheck.Report) {
+	result := map[string]interface{}{
+		"explanation": content,
+		"patch":       nil,
+		"dsl":         nil,
+	}
+
+	reply := struct {
+		Explanation string          `json:"explanation"`
+		Patch       json.RawMessage `json:"patch"`
+	}{}
+	if err := json.Unmarshal([]byte(llm.ExtractJSON(content)), &reply); err != nil {
+		result["raw"] = content
+		return result, invalidReply(&dslcheck.Issue{Message: "the reply must be a JSON object with the explanation and patch keys"})
+	}
+	result["explanation"] = reply.Explanation
+
+	if reply.Patch == nil {
+		return result, invalidReply(&dslcheck.Issue{Path: "patch", Message: "the patch is missing"})
+	}
+
+	patch, err := jsonpatch.Decode(reply.Patch)
 	if err != nil {
-		return errResp(c, 500, "AI request failed: "+err.Error())
+		return result, invalidReply(patchIssue(err))
+	}
+	result["patch"] = patch
+
+	scope := body.patchScope()
+	issues := []*dslcheck.Issue{}
+	for i, o := range patch {
+		for _, pointer := range o.Modified() {
+			if !inPatchScope(pointer, scope) {
+				issues = append(issues, &dslcheck.Issue{
+					Path:    fmt.Sprintf("patch[%d]", i),
+					Message: fmt.Sprintf("the path %q is outside of the allowed paths (%s)", pointer, strings.Join(scope, ", ")),
+				})
+			}
+		}
+	}
+	if len(issues) > 0 {
+		return result, invalidReply(issues...)
 	}
-	defer resp.Body.Close()
 
-	respBody, err := io.ReadAll(resp.Body)
+	current, err := json.Marshal(body.CurrentDSL)
 	if err != nil {
-		return errResp(c, 500, "Failed to read AI response")
+		return result, invalidReply(&dslcheck.Issue{Message: err.Error()})
 	}
 
-	if resp.StatusCode != 200 {
-		// Return 502 instead of forwarding OpenAI's status code directly,
-		// because a 401 from OpenAI would trigger the client's auth interceptor
-		// and log the user out.
-		return errResp(c, 502, "AI service error: "+string(respBody))
+	dsl, err := patch.Apply(current)
+	if err != nil {
+		return result, invalidReply(patchIssue(err))
 	}
+	result["dsl"] = json.RawMessage(dsl)
+
+	// only the problems introduced by the patch are reported,
+	// the ones already in the page aren't for the model to fix
+	return result, dslcheck.ValidateChanges(dsl, current, componentCatalog)
+}
 
-	var openaiResp struct {
-		Choices []struct {
-			Message struct {
-				Content string `json:"content"`
-			} `json:"message"`
-		} `json:"choices"`
+// inPatchScope checks whether pointer is one of the scope pointers or a child of them.
+func inPatchScope(pointer string, scope []string) bool {
+	for _, s := range scope {
+		if pointer == s || strings.HasPrefix(pointer, s+"/") {
+			return true
+		}
 	}
-	if err := json.Unmarshal(respBody, &openaiResp); err != nil {
-		return errResp(c, 500, "Failed to parse AI response")
+	return false
+}
+
+// patchIssue converts a patch decode/apply error into a validation issue.
+func patchIssue(err error) *dslcheck.Issue {
+	var opErr *jsonpatch.OpError
+	if errors.As(err, &opErr) {
+		return &dslcheck.Issue{Path: fmt.Sprintf("patch[%d]", opErr.Index), Message: opErr.Err.Error()}
 	}
+	return &dslcheck.Issue{Path: "patch", Message: err.Error()}
+}
+
+// invalidReply creates a failed validation report with the provided issues.
+func invalidReply(issues ...*dslcheck.Issue) *dslcheck.Report {
+	return &dslcheck.Report{Errors: issues, Warnings: []*dslcheck.Issue{}}
+}
+
+// --- Threads ---
 
-	if len(openaiResp.Choices) == 0 {
-		return errResp(c, 500, "AI returned no response")
+// maxThreadHistory is the max number of previous thread messages
+// sent with a new chat message.
+const maxThreadHistory = 20
+
+// maxThreadTitleLength is the max length of the thread titles
+// (created from the first thread message).
+const maxThreadTitleLength = 80
+
+// chatThread returns the thread the chat message belongs to (or nil
+// for the chats without app), writing the error response if the
+// thread or app can't be accessed.
+//
+// A new thread is only saved once it has its first reply.
+func (api *aiApi) chatThread(c echo.Context, body *chatBody) (*models.AiThread, error) {
+	if body.ThreadId != "" {
+		thread, err := api.findThread(c, body.ThreadId)
+		if err != nil {
+			return nil, err
+		}
+
+		app, err := api.dao.FindPblAppById(thread.AppId)
+		if err != nil {
+			return nil, denyResp(c, 404, "Application not found")
+		}
+		if err := api.ob.requireAppRole(c, app, models.AppRoleEditor); err != nil {
+			return nil, err
+		}
+
+		return thread, nil
 	}
 
-	content := openaiResp.Choices[0].Message.Content
+	if body.AppId == "" {
+		return nil, nil
+	}
 
-	var aiResult map[string]interface{}
-	if err := json.Unmarshal([]byte(content), &aiResult); err != nil {
-		return okResp(c, map[string]interface{}{
-			"explanation": content,
-			"dsl":         nil,
-			"raw":         content,
+	app, err := api.dao.FindPblAppBySlug(body.AppId, nil)
+	if err != nil || app == nil {
+		return nil, denyResp(c, 404, "Application not found")
+	}
+	if err := api.ob.requireAppRole(c, app, models.AppRoleEditor); err != nil {
+		return nil, err
+	}
+
+	title := []rune(strings.TrimSpace(body.Message))
+	if len(title) > maxThreadTitleLength {
+		title = append(title[:maxThreadTitleLength-1], '…')
+	}
+
+	return &models.AiThread{
+		AppId:    app.Id,
+		UserId:   api.ob.actorId(c),
+		Title:    string(title),
+		Messages: []*models.AiThreadMessage{},
+	}, nil
+}
+
+// recordChat appends the chat message and its reply explanation
+// to the thread (if any) and adds the thread id to the chat result.
+//
+// A failure is only reported in the app logs, the reply is still returned.
+func (api *aiApi) recordChat(thread *models.AiThread, body *chatBody, result map[string]interface{}) {
+	if thread == nil {
+		return
+	}
+
+	explanation, _ := result["explanation"].(string)
+	thread.AddMessage(llm.RoleUser, body.Message)
+	thread.AddMessage(llm.RoleAssistant, explanation)
+
+	if err := api.dao.SavePblAiThread(thread); err != nil {
+		api.app.Logger().Error("Failed to save the AI thread", "app", thread.AppId, "error", err.Error())
+		return
+	}
+
+	result["threadId"] = thread.Id
+}
+
+// findThread returns the thread of the request actor with the provided id.
+func (api *aiApi) findThread(c echo.Context, id string) (*models.AiThread, error) {
+	thread, err := api.dao.FindPblAiThreadById(id)
+	if err != nil || thread.UserId != api.ob.actorId(c) {
+		return nil, denyResp(c, 404, "Thread not found")
+	}
+	return thread, nil
+}
+
+func createThreadItem(thread *models.AiThread) map[string]interface{} {
+	return map[string]interface{}{
+		"threadId":     thread.Id,
+		"title":        thread.Title,
+		"messageCount": len(thread.Messages),
+		"createTime":   thread.Created.Time().UnixMilli(),
+		"updateTime":   thread.Updated.Time().UnixMilli(),
+	}
+}
+
+func (api *aiApi) threadsList(c echo.Context) error {
+	if err := api.ob.requireAuth(c); err != nil {
+		return err
+	}
+
+	app, err := api.dao.FindPblAppBySlug(c.QueryParam("appId"), nil)
+	if err != nil || app == nil {
+		return errResp(c, 404, "Application not found")
+	}
+
+	if err := api.ob.requireAppRole(c, app, models.AppRoleEditor); err != nil {
+		return err
+	}
+
+	threads, err := api.dao.FindPblAiThreads(app.Id, api.ob.actorId(c))
+	if err != nil {
+		return errResp(c, 500, "Failed to list threads")
+	}
+
+	result := []interface{}{}
+	for _, t := range threads {
+		result = append(result, createThreadItem(t))
+	}
+	return okResp(c, result)
+}
+
+func (api *aiApi) threadView(c echo.Context) error {
+	if err := api.ob.requireAuth(c); err != nil {
+		return err
+	}
+
+	thread, err := api.findThread(c, c.PathParam("id"))
+	if err != nil {
+		return err
+	}
+
+	messages := []interface{}{}
+	for _, m := range thread.Messages {
+		messages = append(messages, map[string]interface{}{
+			"role":       m.Role,
+			"content":    m.Content,
+			"createTime": m.Created.Time().UnixMilli(),
 		})
 	}
 
-	return okResp(c, aiResult)
+	item := createThreadItem(thread)
+	item["messages"] = messages
+	return okResp(c, item)
+}
+
+func (api *aiApi) threadDelete(c echo.Context) error {
+	if err := api.ob.requireAuth(c); err != nil {
+		return err
+	}
+
+	thread, err := api.findThread(c, c.PathParam("id"))
+	if err != nil {
+		return err
+	}
+
+	if err := api.dao.DeletePblAiThread(thread); err != nil {
+		return errResp(c, 400, err.Error())
+	}
+	return okResp(c, true)
 }
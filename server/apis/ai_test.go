@@ -0,0 +1,107 @@
+package apis
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/pedrozadotdev/pocketblocks/server/dslcheck"
+	"github.com/pedrozadotdev/pocketblocks/server/llm"
+)
+
+const testChatDsl = `{"ui": {"layout": {
+	"a1": {"i": "a1", "x": 0, "y": 0, "w": 12, "h": 5},
+	"b2": {"i": "b2", "x": 12, "y": 0, "w": 12, "h": 5}
+}, "items": {
+	"a1": {"compType": "input", "name": "input1", "comp": {"label": "Name"}},
+	"b2": {"compType": "button", "name": "button1", "comp": {"text": "Save"}}
+}}, "queries": []}`
+
+func newTestPatchBody(t *testing.T, selectedComp string) *chatBody {
+	t.Helper()
+
+	body := &chatBody{Message: "rename the field", Mode: chatModePatch, SelectedComp: selectedComp}
+	if err := json.Unmarshal([]byte(testChatDsl), &body.CurrentDSL); err != nil {
+		t.Fatal(err)
+	}
+	return body
+}
+
+func TestChatBodyPatchScope(t *testing.T) {
+	body := newTestPatchBody(t, "button1")
+
+	expected := []string{"/ui/items/b2", "/ui/layout/b2"}
+	if scope := body.patchScope(); !slices.Equal(scope, expected) {
+		t.Fatalf("Expected scope %v, got %v", expected, scope)
+	}
+	if err := body.validate(); err != nil {
+		t.Fatalf("Expected a valid body, got %v", err)
+	}
+
+	body.Scope = []string{"/queries"}
+	if scope := body.patchScope(); !slices.Equal(scope, body.Scope) {
+		t.Fatalf("Expected the explicit scope, got %v", scope)
+	}
+
+	for _, selectedComp := range []string{"", "missing1"} {
+		if err := newTestPatchBody(t, selectedComp).validate(); err == nil {
+			t.Fatalf("[%q] Expected a patch without scope to be rejected", selectedComp)
+		}
+	}
+}
+
+func TestGeneratePatchScope(t *testing.T) {
+	scenarios := []struct {
+		name          string
+		patch         string
+		expectValid   bool
+		expectedCalls int
+	}{
+		{
+			"patch of the selected component",
+			`[{"op": "replace", "path": "/ui/items/a1/comp/label", "value": "Full name"}]`,
+			true,
+			1,
+		},
+		{
+			"patch outside of the selected component",
+			`[{"op": "replace", "path": "/ui/items/b2/comp/text", "value": "Submit"}]`,
+			false,
+			maxRepairAttempts + 1,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			body := newTestPatchBody(t, "input1")
+
+			calls := 0
+			repairs := 0
+			reply := `{"explanation": "done", "patch": ` + s.patch + `}`
+
+			result, err := generate(newChatRequest(body, nil), body.checkReply, func(req *llm.Request) (*llm.Response, error) {
+				calls++
+				return &llm.Response{Content: reply}, nil
+			}, func(attempt int, report *dslcheck.Report) error {
+				repairs++
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if calls != s.expectedCalls || repairs != s.expectedCalls-1 {
+				t.Fatalf("Expected %d calls and %d repairs, got %d and %d", s.expectedCalls, s.expectedCalls-1, calls, repairs)
+			}
+
+			report := result["validation"].(*dslcheck.Report)
+			if report.Valid != s.expectValid {
+				t.Fatalf("Expected valid %v, got %v (%s)", s.expectValid, report.Valid, report.Prompt())
+			}
+			if !s.expectValid && !strings.Contains(report.Prompt(), "outside of the allowed paths") {
+				t.Fatalf("Expected an out of scope error, got %s", report.Prompt())
+			}
+		})
+	}
+}
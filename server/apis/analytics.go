@@ -0,0 +1,140 @@
+package apis
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pedrozadotdev/pocketblocks/server/daos"
+	"github.com/pedrozadotdev/pocketblocks/server/models"
+	"github.com/pocketbase/pocketbase/apis"
+)
+
+const (
+	// defaultAnalyticsDays is the analytics period when no from date is set.
+	defaultAnalyticsDays = 30
+	// defaultAnalyticsTop is the default number of top apps.
+	defaultAnalyticsTop = 10
+)
+
+func BindAnalyticsApi(dao *daos.Dao, g *echo.Group, logMiddleware echo.MiddlewareFunc) {
+	api := analyticsApi{dao: dao}
+
+	subGroup := g.Group("/analytics")
+	subGroup.GET("/apps", api.apps, apis.RequireAdminAuth())
+}
+
+type analyticsApi struct {
+	dao *daos.Dao
+}
+
+type analyticsApp struct {
+	Id   string `json:"id"`
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+}
+
+type analyticsDailyViewers struct {
+	*daos.PblAppDailyViewers
+	App *analyticsApp `json:"app"`
+}
+
+type analyticsUsage struct {
+	*daos.PblAppUsage
+	App *analyticsApp `json:"app"`
+}
+
+// apps returns the apps usage between the from and to days (both included,
+// formatted as YYYY-MM-DD in UTC): the daily active viewers per app,
+// the most viewed apps and the apps without any view.
+func (api *analyticsApi) apps(c echo.Context) error {
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	if v := c.QueryParam("to"); v != "" {
+		t, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			return apis.NewBadRequestError("Invalid to date (YYYY-MM-DD).", err)
+		}
+		to = t
+	}
+
+	from := to.AddDate(0, 0, -defaultAnalyticsDays+1)
+	if v := c.QueryParam("from"); v != "" {
+		t, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			return apis.NewBadRequestError("Invalid from date (YYYY-MM-DD).", err)
+		}
+		from = t
+	}
+
+	if from.After(to) {
+		return apis.NewBadRequestError("The from date must be before the to date.", nil)
+	}
+
+	limit := int64(defaultAnalyticsTop)
+	if v := c.QueryParam("limit"); v != "" {
+		l, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || l < 1 {
+			return apis.NewBadRequestError("Invalid limit.", err)
+		}
+		limit = l
+	}
+
+	// the to day is included
+	end := to.AddDate(0, 0, 1)
+
+	daily, err := api.dao.FindPblAppDailyViewers(from, end)
+	if err != nil {
+		return apis.NewBadRequestError("Failed to load the daily viewers.", err)
+	}
+
+	usage, err := api.dao.FindPblAppsUsage(from, end, limit)
+	if err != nil {
+		return apis.NewBadRequestError("Failed to load the top apps.", err)
+	}
+
+	unused, err := api.dao.FindPblUnusedApps(from, end)
+	if err != nil {
+		return apis.NewBadRequestError("Failed to load the unused apps.", err)
+	}
+
+	appsById := map[string]*analyticsApp{}
+	findApp := func(id string) *analyticsApp {
+		if app, ok := appsById[id]; ok {
+			return app
+		}
+		app := &analyticsApp{Id: id}
+		if model, err := api.dao.FindPblAppById(id); err == nil {
+			app = newAnalyticsApp(model)
+		}
+		appsById[id] = app
+		return app
+	}
+
+	dailyResult := make([]*analyticsDailyViewers, 0, len(daily))
+	for _, d := range daily {
+		dailyResult = append(dailyResult, &analyticsDailyViewers{d, findApp(d.AppId)})
+	}
+
+	topResult := make([]*analyticsUsage, 0, len(usage))
+	for _, u := range usage {
+		topResult = append(topResult, &analyticsUsage{u, findApp(u.AppId)})
+	}
+
+	unusedResult := make([]*analyticsApp, 0, len(unused))
+	for _, app := range unused {
+		unusedResult = append(unusedResult, newAnalyticsApp(app))
+	}
+
+	return c.JSON(http.StatusOK, map[string]any{
+		"from":         from.Format(time.DateOnly),
+		"to":           to.Format(time.DateOnly),
+		"dailyViewers": dailyResult,
+		"topApps":      topResult,
+		"unusedApps":   unusedResult,
+	})
+}
+
+func newAnalyticsApp(app *models.Application) *analyticsApp {
+	return &analyticsApp{Id: app.Id, Slug: app.Slug, Name: app.Name}
+}
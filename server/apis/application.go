@@ -10,6 +10,7 @@ import (
 	"github.com/labstack/echo/v5"
 	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/tools/list"
 	"github.com/pocketbase/pocketbase/tools/search"
 	pbModels "github.com/pocketbase/pocketbase/models"
 )
@@ -23,6 +24,7 @@ func BindApplicationApi(dao *daos.Dao, g *echo.Group, logMiddleware echo.Middlew
 	subGroup.GET("/:slug", api.view)
 	subGroup.PATCH("/:slug", api.update, apis.RequireAdminAuth(), logMiddleware)
 	subGroup.DELETE("/:slug", api.delete, apis.RequireAdminAuth(), logMiddleware)
+	subGroup.POST("/:slug/copy", api.copy, apis.RequireAdminAuth(), logMiddleware)
 	// Change manifest route to use query parameter
 	subGroup.GET("/manifest", api.manifest)
 }
@@ -33,7 +35,7 @@ type applicationApi struct {
 
 func (api *applicationApi) list(c echo.Context) error {
 	fieldResolver := search.NewSimpleFieldResolver(
-		"id", "name", "slug", "type", "status", "allUsers", "groups", "users", "appDSL", "editDSL", "folder", "created", "updated",
+		"id", "name", "slug", "type", "status", "allUsers", "groups", "users", "appDSL", "editDSL", "folder", "isTemplate", "templateId", "created", "updated",
 	)
 
 	applications := []*models.Application{}
@@ -60,6 +62,11 @@ func (api *applicationApi) list(c echo.Context) error {
 			groupIds = append(groupIds, g.Id)
 		}
 
+		folderIds, err := api.dao.FindPblFolderIdsByMember(info.AuthRecord.Id, groupIds)
+		if err != nil {
+			return apis.NewApiError(500, "Something went wrong", err)
+		}
+
 		var filterExpr dbx.Expression = dbx.Or(
 			dbx.HashExp{"public": true},
 			dbx.HashExp{"allUsers": true},
@@ -77,6 +84,11 @@ func (api *applicationApi) list(c echo.Context) error {
 				dbx.Like("users", info.AuthRecord.Id),
 			)
 		}
+
+		// apps inside a shared folder are accessible as well
+		if len(folderIds) > 0 {
+			filterExpr = dbx.Or(filterExpr, dbx.In("folder", list.ToInterfaceSlice(folderIds)...))
+		}
 		query = query.AndWhere(filterExpr)
 	}
 
@@ -136,7 +148,8 @@ func (api *applicationApi) view(c echo.Context) error {
 	return c.JSON(http.StatusOK, app)
 }
 
-// userIsAuthorized checks if the user is in the app's Users, Groups, if AllUsers is true, or if app is Public
+// userIsAuthorized checks if the user is in the app's Users, Groups, if AllUsers is true, if app is Public
+// or if the access was granted on the app folder (or one of its ancestors)
 func (api *applicationApi) userIsAuthorized(app *models.Application, info *pbModels.RequestInfo) bool {
 	if info.AuthRecord == nil {
 		return false
@@ -161,26 +174,40 @@ func (api *applicationApi) userIsAuthorized(app *models.Application, info *pbMod
 		return true
 	}
 
+	if len(app.Groups) == 0 && !app.FolderId.Valid {
+		return false
+	}
+
+	groups, err := api.dao.FindRecordsByFilter(
+		"groups",
+		"users.id ?= \""+userId+"\"",
+		"-created",
+		500,
+		0,
+	)
+	if err != nil {
+		return false
+	}
+
+	groupIds := []string{}
+	for _, g := range groups {
+		groupIds = append(groupIds, g.Id)
+	}
+
 	// Check if user is in any allowed group
-	if len(app.Groups) > 0 {
-		groups, err := api.dao.FindRecordsByFilter(
-			"groups",
-			"users.id ?= \""+userId+"\"",
-			"-created",
-			500,
-			0,
-		)
-		if err == nil {
-			for _, g := range groups {
-				for _, allowedGroup := range app.Groups {
-					if g.Id == allowedGroup {
-						return true
-					}
-				}
+	for _, gId := range groupIds {
+		for _, allowedGroup := range app.Groups {
+			if gId == allowedGroup {
+				return true
 			}
 		}
 	}
 
+	// Check if access was granted on the app folder
+	if app.FolderId.Valid {
+		return api.dao.PblFolderHasMember(app.FolderId.String, userId, groupIds)
+	}
+
 	return false
 }
 
@@ -200,6 +227,27 @@ func (api *applicationApi) create(c echo.Context) error {
 	return c.JSON(http.StatusOK, application)
 }
 
+func (api *applicationApi) copy(c echo.Context) error {
+	source, err := api.dao.FindPblAppBySlug(c.PathParam("slug"), nil)
+	if err != nil || source == nil {
+		return apis.NewNotFoundError("", err)
+	}
+
+	form := forms.NewApplicationCopy(api.dao, source)
+
+	// load request
+	if err := c.Bind(form); err != nil {
+		return apis.NewBadRequestError("Failed to load the submitted data due to invalid formatting.", err)
+	}
+
+	application, err := form.Submit()
+	if err != nil {
+		return apis.NewBadRequestError("Failed to load the submitted data. Try again later.", err)
+	}
+
+	return c.JSON(http.StatusOK, application)
+}
+
 func (api *applicationApi) update(c echo.Context) error {
 	slug := c.PathParam("slug")
 	if slug == "" {
@@ -0,0 +1,126 @@
+package apis
+
+import (
+	"encoding/csv"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pedrozadotdev/pocketblocks/server/daos"
+	"github.com/pedrozadotdev/pocketblocks/server/models"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/tools/search"
+)
+
+// maxAuditLogExport is the max number of audit log entries in a single export.
+const maxAuditLogExport = 10000
+
+func BindAuditLogApi(dao *daos.Dao, g *echo.Group, logMiddleware echo.MiddlewareFunc) {
+	api := auditLogApi{dao: dao}
+
+	subGroup := g.Group("/audit-logs")
+	subGroup.GET("", api.list, apis.RequireAdminAuth())
+	subGroup.GET("/export", api.export, apis.RequireAdminAuth())
+	subGroup.GET("/:id", api.view, apis.RequireAdminAuth())
+}
+
+type auditLogApi struct {
+	dao *daos.Dao
+}
+
+func auditLogFieldResolver() search.FieldResolver {
+	return search.NewSimpleFieldResolver(
+		"id", "actor", "actorType", "actorName", "action", "targetType",
+		"target", "targetName", "ip", "created", "updated",
+	)
+}
+
+func (api *auditLogApi) view(c echo.Context) error {
+	id := c.PathParam("id")
+	if id == "" {
+		return apis.NewNotFoundError("", nil)
+	}
+
+	log, err := api.dao.FindPblAuditLogById(id)
+	if err != nil || log == nil {
+		return apis.NewNotFoundError("", nil)
+	}
+
+	return c.JSON(http.StatusOK, log)
+}
+
+func (api *auditLogApi) list(c echo.Context) error {
+	logs := []*models.AuditLog{}
+
+	result, err := search.NewProvider(auditLogFieldResolver()).
+		Query(api.dao.PblAuditLogQuery()).
+		ParseAndExec(c.QueryParams().Encode(), &logs)
+
+	if err != nil {
+		return apis.NewBadRequestError("", err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// export returns the audit log entries matching the optional filter
+// (newest first) as a CSV or JSON (default) file.
+func (api *auditLogApi) export(c echo.Context) error {
+	format := c.QueryParam("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "csv" {
+		return apis.NewBadRequestError("Invalid export format (json or csv).", nil)
+	}
+
+	query := api.dao.PblAuditLogQuery()
+	if filter := c.QueryParam("filter"); filter != "" {
+		expr, err := search.FilterData(filter).BuildExpr(auditLogFieldResolver())
+		if err != nil {
+			return apis.NewBadRequestError("Invalid filter.", err)
+		}
+		query = query.AndWhere(expr)
+	}
+
+	logs := []*models.AuditLog{}
+	if err := query.OrderBy("created DESC").Limit(maxAuditLogExport).All(&logs); err != nil {
+		return apis.NewBadRequestError("Failed to load the audit logs.", err)
+	}
+
+	c.Response().Header().Set("Content-Disposition", "attachment; filename=\"audit-logs."+format+"\"")
+
+	if format == "json" {
+		return c.JSON(http.StatusOK, logs)
+	}
+
+	var buf strings.Builder
+	w := csv.NewWriter(&buf)
+	w.Write([]string{
+		"id", "created", "actor", "actorType", "actorName", "action",
+		"targetType", "target", "targetName", "ip", "before", "after",
+	})
+	for _, log := range logs {
+		w.Write(csvSafeRow(
+			log.Id, log.Created.String(), log.Actor, log.ActorType, log.ActorName, log.Action,
+			log.TargetType, log.Target, log.TargetName, log.Ip, log.Before.String(), log.After.String(),
+		))
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return apis.NewBadRequestError("Failed to export the audit logs.", err)
+	}
+
+	return c.Blob(http.StatusOK, "text/csv; charset=utf-8", []byte(buf.String()))
+}
+
+// csvSafeRow prefixes the cells starting with a formula character with "'",
+// so user provided names can't be evaluated as spreadsheet formulas.
+func csvSafeRow(cells ...string) []string {
+	for i, cell := range cells {
+		if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+			cells[i] = "'" + cell
+		}
+	}
+	return cells
+}
@@ -0,0 +1,75 @@
+package apis
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pedrozadotdev/pocketblocks/server/appbundle"
+	"github.com/pedrozadotdev/pocketblocks/server/daos"
+	"github.com/pocketbase/pocketbase/apis"
+)
+
+// maxBundleSize is the max accepted size of an imported app bundle.
+const maxBundleSize = 100 << 20
+
+func BindBundleApi(dao *daos.Dao, publicDir string, g *echo.Group, logMiddleware echo.MiddlewareFunc) {
+	api := bundleApi{dao: dao, publicDir: publicDir}
+
+	subGroup := g.Group("/applications")
+	subGroup.GET("/:slug/export", api.export, apis.RequireAdminAuth())
+	subGroup.POST("/import", api.importBundle, apis.RequireAdminAuth(), logMiddleware)
+}
+
+type bundleApi struct {
+	dao       *daos.Dao
+	publicDir string
+}
+
+func (api *bundleApi) export(c echo.Context) error {
+	app, err := api.dao.FindPblAppBySlug(c.PathParam("slug"), nil)
+	if err != nil || app == nil {
+		return apis.NewNotFoundError("", err)
+	}
+
+	options := appbundle.ExportOptions{
+		Snapshots: c.QueryParam("snapshots") == "true" || c.QueryParam("snapshots") == "1",
+	}
+
+	var buf bytes.Buffer
+	if err := appbundle.Export(api.dao, api.publicDir, app, options, &buf); err != nil {
+		return apis.NewBadRequestError("Failed to export the application.", err)
+	}
+
+	c.Response().Header().Set("Content-Disposition", "attachment; filename=\""+app.Slug+".zip\"")
+	return c.Blob(http.StatusOK, "application/zip", buf.Bytes())
+}
+
+func (api *bundleApi) importBundle(c echo.Context) error {
+	fh, err := c.FormFile("bundle")
+	if err != nil {
+		return apis.NewBadRequestError("Missing bundle file.", err)
+	}
+	if fh.Size > maxBundleSize {
+		return apis.NewBadRequestError("The bundle file is too large.", nil)
+	}
+
+	f, err := fh.Open()
+	if err != nil {
+		return apis.NewBadRequestError("Failed to read the bundle file.", err)
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return apis.NewBadRequestError("Failed to read the bundle file.", err)
+	}
+
+	result, err := appbundle.Import(api.dao, api.publicDir, bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return apis.NewBadRequestError("Failed to import the bundle.", err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,121 @@
+package apis
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pedrozadotdev/pocketblocks/server/daos"
+	"github.com/pedrozadotdev/pocketblocks/server/forms"
+	"github.com/pedrozadotdev/pocketblocks/server/models"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/tools/search"
+)
+
+func BindDatasourceApi(dao *daos.Dao, g *echo.Group, logMiddleware echo.MiddlewareFunc) {
+	api := datasourceApi{dao: dao}
+
+	subGroup := g.Group("/datasources")
+	subGroup.GET("", api.list, apis.RequireAdminAuth())
+	subGroup.GET("/:id", api.view, apis.RequireAdminAuth())
+	subGroup.POST("", api.create, apis.RequireAdminAuth(), logMiddleware)
+	subGroup.PATCH("/:id", api.update, apis.RequireAdminAuth(), logMiddleware)
+	subGroup.DELETE("/:id", api.delete, apis.RequireAdminAuth(), logMiddleware)
+
+}
+
+type datasourceApi struct {
+	dao *daos.Dao
+}
+
+func (api *datasourceApi) list(c echo.Context) error {
+	fieldResolver := search.NewSimpleFieldResolver(
+		"id", "name", "type", "created", "updated",
+	)
+
+	datasources := []*models.Datasource{}
+
+	result, err := search.NewProvider(fieldResolver).
+		Query(api.dao.PblDatasourceQuery()).
+		ParseAndExec(c.QueryParams().Encode(), &datasources)
+
+	if err != nil {
+		return apis.NewBadRequestError("", err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+
+}
+
+func (api *datasourceApi) view(c echo.Context) error {
+	id := c.PathParam("id")
+	if id == "" {
+		return apis.NewNotFoundError("", nil)
+	}
+
+	datasource, err := api.dao.FindPblDatasourceById(id)
+	if err != nil || datasource == nil {
+		return apis.NewNotFoundError("", nil)
+	}
+
+	return c.JSON(http.StatusOK, datasource)
+}
+
+func (api *datasourceApi) create(c echo.Context) error {
+	form := forms.NewDatasourceUpsert(api.dao, &models.Datasource{})
+
+	// load request
+	if err := c.Bind(form); err != nil {
+		return apis.NewBadRequestError("Failed to load the submitted data due to invalid formatting.", err)
+	}
+
+	datasource, err := form.Submit()
+	if err != nil {
+		return apis.NewBadRequestError("Failed to load the submitted data. Try again later.", err)
+	}
+
+	return c.JSON(http.StatusOK, datasource)
+}
+
+func (api *datasourceApi) update(c echo.Context) error {
+	id := c.PathParam("id")
+	if id == "" {
+		return apis.NewNotFoundError("", nil)
+	}
+
+	datasource, err := api.dao.FindPblDatasourceById(id)
+	if err != nil || datasource == nil {
+		return apis.NewNotFoundError("", err)
+	}
+
+	form := forms.NewDatasourceUpsert(api.dao, datasource)
+
+	// load request
+	if err := c.Bind(form); err != nil {
+		return apis.NewBadRequestError("Failed to load the submitted data due to invalid formatting.", err)
+	}
+
+	datasourceUpdated, err := form.Submit()
+	if err != nil {
+		return apis.NewBadRequestError("Failed to load the submitted data. Try again later.", err)
+	}
+
+	return c.JSON(http.StatusOK, datasourceUpdated)
+}
+
+func (api *datasourceApi) delete(c echo.Context) error {
+	id := c.PathParam("id")
+	if id == "" {
+		return apis.NewNotFoundError("", nil)
+	}
+
+	datasource, err := api.dao.FindPblDatasourceById(id)
+	if err != nil || datasource == nil {
+		return apis.NewNotFoundError("", err)
+	}
+
+	if err := api.dao.DeletePblDatasource(datasource); err != nil {
+		return apis.NewBadRequestError("Failed to delete datasource.", err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,148 @@
+package apis
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pedrozadotdev/pocketblocks/server/daos"
+	"github.com/pedrozadotdev/pocketblocks/server/forms"
+	"github.com/pedrozadotdev/pocketblocks/server/models"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/tools/search"
+)
+
+func BindLibraryQueryApi(dao *daos.Dao, g *echo.Group, logMiddleware echo.MiddlewareFunc) {
+	api := libraryQueryApi{dao: dao}
+
+	subGroup := g.Group("/library-queries")
+	subGroup.GET("", api.list, apis.RequireAdminAuth())
+	subGroup.GET("/:id", api.view, apis.RequireAdminAuth())
+	subGroup.POST("", api.create, apis.RequireAdminAuth(), logMiddleware)
+	subGroup.PATCH("/:id", api.update, apis.RequireAdminAuth(), logMiddleware)
+	subGroup.POST("/:id/publish", api.publish, apis.RequireAdminAuth(), logMiddleware)
+	subGroup.DELETE("/:id", api.delete, apis.RequireAdminAuth(), logMiddleware)
+
+}
+
+type libraryQueryApi struct {
+	dao *daos.Dao
+}
+
+func (api *libraryQueryApi) list(c echo.Context) error {
+	fieldResolver := search.NewSimpleFieldResolver(
+		"id", "name", "editDSL", "publishedDSL", "tag", "commitMessage", "published", "created", "updated",
+	)
+
+	libraryQueries := []*models.LibraryQuery{}
+
+	result, err := search.NewProvider(fieldResolver).
+		Query(api.dao.PblLibraryQueryQuery()).
+		ParseAndExec(c.QueryParams().Encode(), &libraryQueries)
+
+	if err != nil {
+		return apis.NewBadRequestError("", err)
+	}
+
+	return c.JSON(http.StatusOK, result)
+
+}
+
+func (api *libraryQueryApi) view(c echo.Context) error {
+	id := c.PathParam("id")
+	if id == "" {
+		return apis.NewNotFoundError("", nil)
+	}
+
+	libraryQuery, err := api.dao.FindPblLibraryQueryById(id)
+	if err != nil || libraryQuery == nil {
+		return apis.NewNotFoundError("", nil)
+	}
+
+	return c.JSON(http.StatusOK, libraryQuery)
+}
+
+func (api *libraryQueryApi) create(c echo.Context) error {
+	form := forms.NewLibraryQueryUpsert(api.dao, &models.LibraryQuery{})
+
+	// load request
+	if err := c.Bind(form); err != nil {
+		return apis.NewBadRequestError("Failed to load the submitted data due to invalid formatting.", err)
+	}
+
+	libraryQuery, err := form.Submit()
+	if err != nil {
+		return apis.NewBadRequestError("Failed to load the submitted data. Try again later.", err)
+	}
+
+	return c.JSON(http.StatusOK, libraryQuery)
+}
+
+func (api *libraryQueryApi) update(c echo.Context) error {
+	id := c.PathParam("id")
+	if id == "" {
+		return apis.NewNotFoundError("", nil)
+	}
+
+	libraryQuery, err := api.dao.FindPblLibraryQueryById(id)
+	if err != nil || libraryQuery == nil {
+		return apis.NewNotFoundError("", err)
+	}
+
+	form := forms.NewLibraryQueryUpsert(api.dao, libraryQuery)
+
+	// load request
+	if err := c.Bind(form); err != nil {
+		return apis.NewBadRequestError("Failed to load the submitted data due to invalid formatting.", err)
+	}
+
+	libraryQueryUpdated, err := form.Submit()
+	if err != nil {
+		return apis.NewBadRequestError("Failed to load the submitted data. Try again later.", err)
+	}
+
+	return c.JSON(http.StatusOK, libraryQueryUpdated)
+}
+
+func (api *libraryQueryApi) publish(c echo.Context) error {
+	id := c.PathParam("id")
+	if id == "" {
+		return apis.NewNotFoundError("", nil)
+	}
+
+	libraryQuery, err := api.dao.FindPblLibraryQueryById(id)
+	if err != nil || libraryQuery == nil {
+		return apis.NewNotFoundError("", err)
+	}
+
+	form := forms.NewLibraryQueryPublish(api.dao, libraryQuery)
+
+	// load request
+	if err := c.Bind(form); err != nil {
+		return apis.NewBadRequestError("Failed to load the submitted data due to invalid formatting.", err)
+	}
+
+	libraryQueryPublished, err := form.Submit()
+	if err != nil {
+		return apis.NewBadRequestError("Failed to load the submitted data. Try again later.", err)
+	}
+
+	return c.JSON(http.StatusOK, libraryQueryPublished)
+}
+
+func (api *libraryQueryApi) delete(c echo.Context) error {
+	id := c.PathParam("id")
+	if id == "" {
+		return apis.NewNotFoundError("", nil)
+	}
+
+	libraryQuery, err := api.dao.FindPblLibraryQueryById(id)
+	if err != nil || libraryQuery == nil {
+		return apis.NewNotFoundError("", err)
+	}
+
+	if err := api.dao.DeletePblLibraryQuery(libraryQuery); err != nil {
+		return apis.NewBadRequestError("Failed to delete library query.", err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
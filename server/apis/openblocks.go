@@ -1,8 +1,15 @@
 package apis
 
 import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
 	"encoding/json"
+	"maps"
 	"net/http"
+	"reflect"
 	"slices"
 	"strconv"
 	"strings"
@@ -10,17 +17,23 @@ import (
 
 	"github.com/labstack/echo/v5"
 	"github.com/pedrozadotdev/pocketblocks/server/daos"
+	"github.com/pedrozadotdev/pocketblocks/server/datasources"
 	"github.com/pedrozadotdev/pocketblocks/server/forms"
 	"github.com/pedrozadotdev/pocketblocks/server/models"
 	"github.com/pedrozadotdev/pocketblocks/server/utils"
 	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/apis"
 	pbModels "github.com/pocketbase/pocketbase/models"
 	"github.com/pocketbase/pocketbase/tokens"
+	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/types"
 )
 
 const cookieName = "pb_auth"
 
+const queryExecuteTimeout = 30 * time.Second
+
 type openblocksApi struct {
 	app *pocketbase.PocketBase
 	dao *daos.Dao
@@ -46,8 +59,15 @@ func BindOpenblocksApi(app *pocketbase.PocketBase, dao *daos.Dao, e *echo.Echo)
 	e.GET("/api/v1/applications/:slug/view", api.applicationView)
 	e.GET("/api/v1/applications/:slug/permissions", api.applicationPermissionsGet)
 	e.PUT("/api/v1/applications/:slug/permissions", api.applicationPermissionsUpdate)
+	e.PUT("/api/v1/applications/:slug/permissions/:permId", api.applicationPermissionsRoleUpdate)
 	e.DELETE("/api/v1/applications/:slug/permissions/:permId", api.applicationPermissionsDelete)
 	e.POST("/api/v1/applications/:slug/publish", api.applicationPublish)
+	e.POST("/api/v1/applications/:slug/copy", api.applicationCopy)
+	e.GET("/api/v1/applications/:slug/releases", api.releasesList)
+	e.POST("/api/v1/applications/:slug/releases/:id/approve", api.releaseApprove)
+	e.POST("/api/v1/applications/:slug/releases/:id/reject", api.releaseReject)
+	e.POST("/api/v1/applications/:slug/releases/:id/rollback", api.releaseRollback)
+	e.GET("/api/v1/applications/:slug/releases/:id", api.releaseView)
 	e.GET("/api/v1/applications/:slug", api.applicationView)
 	e.POST("/api/v1/applications", api.applicationCreate)
 	e.PUT("/api/v1/applications/:slug", api.applicationUpdate)
@@ -57,6 +77,8 @@ func BindOpenblocksApi(app *pocketbase.PocketBase, dao *daos.Dao, e *echo.Echo)
 	e.PUT("/api/applications/restore/:slug", api.applicationRestore)
 	e.GET("/api/applications/recycle/list", api.applicationsRecycleList)
 	e.PUT("/api/applications/:slug/public-to-all", api.applicationPublicToAll)
+	e.GET("/api/applications/templates", api.applicationTemplatesList)
+	e.PUT("/api/applications/:slug/template", api.applicationTemplate)
 
 	// Folders
 	e.GET("/api/folders/elements", api.foldersElements)
@@ -69,9 +91,32 @@ func BindOpenblocksApi(app *pocketbase.PocketBase, dao *daos.Dao, e *echo.Echo)
 	e.GET("/api/v1/groups/list", api.groupsList)
 
 	// Snapshots
+	e.GET("/api/application/history-snapshots/:appSlug/diff", api.snapshotDiff)
 	e.GET("/api/application/history-snapshots/:appSlug/:id", api.snapshotView)
 	e.GET("/api/application/history-snapshots/:appSlug", api.snapshotList)
 	e.POST("/api/application/history-snapshots", api.snapshotCreate)
+	e.POST("/api/application/history-snapshots/:appSlug/:id/restore", api.snapshotRestore)
+
+	// Datasources
+	e.GET("/api/v1/organizations/:orgId/datasourceTypes", api.datasourceTypes)
+	e.GET("/api/v1/datasources/listByApp", api.datasourcesListByApp)
+	e.GET("/api/v1/datasources/listByOrg", api.datasourcesListByOrg)
+	e.POST("/api/v1/datasources", api.datasourceCreate)
+	e.PUT("/api/v1/datasources/:id", api.datasourceUpdate)
+	e.DELETE("/api/v1/datasources/:id", api.datasourceDelete)
+
+	// Queries
+	e.POST("/api/v1/query/execute", api.queryExecute)
+
+	// Library Queries
+	e.GET("/api/library-queries/listByOrg", api.libraryQueriesList)
+	e.GET("/api/library-queries/dropDownList", api.libraryQueriesDropDownList)
+	e.POST("/api/library-queries", api.libraryQueryCreate)
+	e.PUT("/api/library-queries/:id", api.libraryQueryUpdate)
+	e.DELETE("/api/library-queries/:id", api.libraryQueryDelete)
+	e.POST("/api/library-queries/:id/publish", api.libraryQueryPublish)
+	e.GET("/api/library-query-records", api.libraryQueryRecordView)
+	e.GET("/api/library-query-records/listByLibraryQueryId", api.libraryQueryRecordList)
 
 	// Configs
 	e.GET("/api/v1/configs", api.configsView)
@@ -86,9 +131,6 @@ func BindOpenblocksApi(app *pocketbase.PocketBase, dao *daos.Dao, e *echo.Echo)
 	emptyList := func(c echo.Context) error { return okResp(c, []interface{}{}) }
 	e.GET("/api/misc/js-library/recommendations", emptyList)
 	e.GET("/api/misc/js-library/metas", emptyList)
-	e.GET("/api/v1/organizations/:orgId/datasourceTypes", emptyList)
-	e.GET("/api/v1/datasources/listByApp", emptyList)
-	e.GET("/api/library-queries/dropDownList", emptyList)
 	e.GET("/api/v1/datasources/jsDatasourcePlugins", emptyList)
 
 	// Avatar upload
@@ -111,6 +153,15 @@ func errResp(c echo.Context, status int, msg string) error {
 	})
 }
 
+// denyResp writes the error response and returns a non nil error,
+// so the handlers guarded by the require* helpers stop right away.
+func denyResp(c echo.Context, status int, msg string) error {
+	if err := errResp(c, status, msg); err != nil {
+		return err
+	}
+	return apis.NewApiError(status, msg, nil)
+}
+
 // --- Auth helpers ---
 
 func (api *openblocksApi) getAuthToken(c echo.Context) string {
@@ -162,14 +213,88 @@ func (api *openblocksApi) isLoggedIn(c echo.Context) bool {
 
 func (api *openblocksApi) requireAuth(c echo.Context) error {
 	if !api.isLoggedIn(c) {
-		return errResp(c, 401, "Unauthorized")
+		return denyResp(c, 401, "Unauthorized")
 	}
 	return nil
 }
 
 func (api *openblocksApi) requireAdmin(c echo.Context) error {
 	if !api.isAdmin(c) {
-		return errResp(c, 401, "Unauthorized")
+		return denyResp(c, 401, "Unauthorized")
+	}
+	return nil
+}
+
+// canViewApp checks if the current request is allowed to view the app
+func (api *openblocksApi) canViewApp(c echo.Context, app *models.Application) bool {
+	return api.appRole(c, app) != ""
+}
+
+// userGroupIds returns the ids of the groups the auth record belongs to
+// (cached for the duration of the request).
+func (api *openblocksApi) userGroupIds(c echo.Context, authRecord *pbModels.Record) []string {
+	if cached, ok := c.Get("pblUserGroupIds").([]string); ok {
+		return cached
+	}
+
+	groups, _ := api.app.Dao().FindRecordsByFilter(
+		"groups",
+		"users.id ?= \""+authRecord.Id+"\"",
+		"-created", 500, 0,
+	)
+	groupIds := []string{}
+	for _, g := range groups {
+		groupIds = append(groupIds, g.Id)
+	}
+
+	c.Set("pblUserGroupIds", groupIds)
+	return groupIds
+}
+
+// appRole returns the highest role the current request has on the app
+// (or an empty string if it has no access at all).
+func (api *openblocksApi) appRole(c echo.Context, app *models.Application) string {
+	if api.isAdmin(c) {
+		return models.AppRoleOwner
+	}
+
+	role := ""
+	grant := func(r string) {
+		if models.AppRoleGreaterOrEqual(r, role) {
+			role = r
+		}
+	}
+
+	if authRecord := api.getAuthRecord(c); authRecord != nil {
+		if slices.Contains(app.Users, authRecord.Id) {
+			grant(app.MemberRole(authRecord.Id + "|USER"))
+		}
+		for _, gId := range api.userGroupIds(c, authRecord) {
+			if slices.Contains(app.Groups, gId) {
+				grant(app.MemberRole(gId + "|GROUP"))
+			}
+		}
+		if app.AllUsers {
+			grant(app.MemberRole("all_users|GROUP"))
+		}
+
+		// folder grants give view access to every app inside it
+		if role == "" && app.FolderId.Valid &&
+			api.dao.PblFolderHasMember(app.FolderId.String, authRecord.Id, api.userGroupIds(c, authRecord)) {
+			role = models.AppRoleViewer
+		}
+	}
+
+	if role == "" && app.Public {
+		role = models.AppRoleViewer
+	}
+
+	return role
+}
+
+func (api *openblocksApi) requireAppRole(c echo.Context, app *models.Application, role string) error {
+	if !models.AppRoleGreaterOrEqual(api.appRole(c, app), role) {
+		return denyResp(c, 401, "Unauthorized")
 	}
 	return nil
 }
@@ -197,6 +322,168 @@ func clearAuthCookie(c echo.Context) {
 	c.SetCookie(cookie)
 }
 
+// --- Audit helpers ---
+
+// auditTarget identifies the resource affected by an audited action.
+type auditTarget struct {
+	kind string
+	id   string
+	name string
+}
+
+func appAuditTarget(app *models.Application) auditTarget {
+	return auditTarget{kind: models.AuditTargetApp, id: app.Id, name: app.Slug}
+}
+
+func folderAuditTarget(folder *models.Folder) auditTarget {
+	return auditTarget{kind: models.AuditTargetFolder, id: folder.Id, name: folder.Name}
+}
+
+// audit records an action of the request actor in the audit log.
+//
+// A failed audit is only reported in the app logs, it never fails the request.
+func (api *openblocksApi) audit(c echo.Context, action string, target auditTarget, before, after interface{}) {
+	entry := &models.AuditLog{
+		Action:     action,
+		TargetType: target.kind,
+		Target:     target.id,
+		TargetName: target.name,
+		Ip:         c.RealIP(),
+	}
+
+	if admin := api.getAdmin(c); admin != nil {
+		entry.Actor = admin.Id
+		entry.ActorType = models.AuditActorAdmin
+		entry.ActorName = admin.Email
+	} else if authRecord := api.getAuthRecord(c); authRecord != nil {
+		entry.Actor = authRecord.Id
+		entry.ActorType = models.AuditActorUser
+		entry.ActorName = authRecord.Username()
+		if n := authRecord.GetString("name"); n != "" && n != "NONAME" {
+			entry.ActorName = n
+		}
+	}
+
+	var err error
+	if entry.Before, err = json.Marshal(before); err == nil {
+		entry.After, err = json.Marshal(after)
+	}
+	if err == nil {
+		err = api.dao.SavePblAuditLog(entry)
+	}
+	if err != nil {
+		api.app.Logger().Error("Failed to save the audit log", "action", action, "target", target.id, "error", err.Error())
+	}
+}
+
+// auditApp summarizes the audited app fields (the dsls are left out).
+func auditApp(app *models.Application) map[string]interface{} {
+	return map[string]interface{}{
+		"name":       app.Name,
+		"slug":       app.Slug,
+		"type":       app.Type,
+		"status":     app.Status,
+		"public":     app.Public,
+		"allUsers":   app.AllUsers,
+		"folder":     app.FolderId.String,
+		"groups":     append([]string{}, app.Groups...),
+		"users":      append([]string{}, app.Users...),
+		"roles":      maps.Clone(app.Roles),
+		"isTemplate": app.IsTemplate,
+		"release":    app.ReleaseId,
+	}
+}
+
+func auditFolder(folder *models.Folder) map[string]interface{} {
+	return map[string]interface{}{
+		"name":     folder.Name,
+		"parent":   folder.ParentId.String,
+		"allUsers": folder.AllUsers,
+		"groups":   append([]string{}, folder.Groups...),
+		"users":    append([]string{}, folder.Users...),
+	}
+}
+
+func auditRelease(release *models.Release) map[string]interface{} {
+	return map[string]interface{}{
+		"app":     release.AppId,
+		"version": release.Version,
+		"status":  release.Status,
+	}
+}
+
+func datasourceAuditTarget(ds *models.Datasource) auditTarget {
+	return auditTarget{kind: models.AuditTargetDatasource, id: ds.Id, name: ds.Name}
+}
+
+// auditDatasource summarizes the audited datasource fields
+// (the config is left out since it may hold credentials).
+func auditDatasource(ds *models.Datasource) map[string]interface{} {
+	return map[string]interface{}{
+		"name": ds.Name,
+		"type": ds.Type,
+		"apps": append([]string{}, ds.Apps...),
+	}
+}
+
+func libraryQueryAuditTarget(lq *models.LibraryQuery) auditTarget {
+	return auditTarget{kind: models.AuditTargetLibraryQuery, id: lq.Id, name: lq.Name}
+}
+
+func auditLibraryQuery(lq *models.LibraryQuery) map[string]interface{} {
+	return map[string]interface{}{
+		"name": lq.Name,
+		"tag":  lq.Tag,
+	}
+}
+
+// auditSettings records the settings keys changed between before and after
+// (with their old and new values), one "settings.update" entry per key.
+func (api *openblocksApi) auditSettings(c echo.Context, before, after *models.Settings) {
+	if before == nil || after == nil {
+		return
+	}
+
+	var beforeMap, afterMap map[string]interface{}
+	if raw, err := json.Marshal(before); err == nil {
+		json.Unmarshal(raw, &beforeMap)
+	}
+	if raw, err := json.Marshal(after); err == nil {
+		json.Unmarshal(raw, &afterMap)
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(afterMap)) {
+		if reflect.DeepEqual(beforeMap[key], afterMap[key]) {
+			continue
+		}
+		target := auditTarget{kind: models.AuditTargetSettings, id: key, name: key}
+		if slices.Contains(auditSummarizedSettings, key) {
+			api.audit(c, "settings.update", target, auditValueSummary(beforeMap[key]), auditValueSummary(afterMap[key]))
+		} else {
+			api.audit(c, "settings.update", target, beforeMap[key], afterMap[key])
+		}
+	}
+}
+
+// auditSummarizedSettings are the settings keys holding code or large json
+// blobs, audited with a summary (see [auditValueSummary]) instead of their value.
+var auditSummarizedSettings = []string{"script", "css", "libs", "plugins", "themes"}
+
+// auditValueSummary returns the length and sha256 hash of the value text.
+func auditValueSummary(value interface{}) map[string]interface{} {
+	text, ok := value.(string)
+	if !ok {
+		raw, _ := json.Marshal(value)
+		text = string(raw)
+	}
+
+	hash := sha256.Sum256([]byte(text))
+	return map[string]interface{}{
+		"length": len(text),
+		"sha256": hex.EncodeToString(hash[:]),
+	}
+}
+
 // --- Auth config builder ---
 
 type oauthInfo struct {
@@ -729,7 +1016,7 @@ func (api *openblocksApi) usersAvatarUpload(c echo.Context) error {
 
 // --- Application helpers ---
 
-func (api *openblocksApi) createAppListItem(app *models.Application, isAdm bool) map[string]interface{} {
+func (api *openblocksApi) createAppListItem(c echo.Context, app *models.Application) map[string]interface{} {
 	var appIconUrl interface{}
 	var dsl map[string]interface{}
 	if json.Unmarshal([]byte(app.AppDsl), &dsl) == nil {
@@ -740,9 +1027,9 @@ func (api *openblocksApi) createAppListItem(app *models.Application, isAdm bool)
 		}
 	}
 
-	role := "viewer"
-	if isAdm {
-		role = "owner"
+	role := api.appRole(c, app)
+	if role == "" {
+		role = models.AppRoleViewer
 	}
 
 	return map[string]interface{}{
@@ -754,16 +1041,59 @@ func (api *openblocksApi) createAppListItem(app *models.Application, isAdm bool)
 		"applicationType":  app.Type,
 		"applicationStatus": app.Status,
 		"folderId":         app.FolderId,
-		"lastViewTime":     app.Updated.Time().UnixMilli(),
+		"lastViewTime":     api.lastViewTime(c, app),
 		"lastModifyTime":   app.Updated.Time().UnixMilli(),
 		"publicToAll":      app.Public,
+		"isTemplate":       app.IsTemplate,
 		"folder":           false,
 		"extra":            map[string]interface{}{"appIconUrl": appIconUrl},
 	}
 }
 
+// recordAppView records a view of the app by the request actor
+// (a failure is only reported in the app logs).
+func (api *openblocksApi) recordAppView(c echo.Context, app *models.Application) {
+	view := &models.AppView{
+		AppId:  app.Id,
+		Viewer: api.actorId(c),
+		Mode:   models.AppViewModeEdit,
+	}
+	if strings.HasSuffix(c.Path(), "/view") {
+		view.Mode = models.AppViewModeView
+	}
+
+	if err := api.dao.SavePblAppView(view); err != nil {
+		api.app.Logger().Error("Failed to record the app view", "app", app.Id, "error", err.Error())
+	}
+}
+
+const lastViewsCtxKey = "pblLastViews"
+
+// lastViewTime returns the last time the request actor opened the app
+// (or nil if never). The actor views are loaded once per request.
+func (api *openblocksApi) lastViewTime(c echo.Context, app *models.Application) interface{} {
+	lastViews, ok := c.Get(lastViewsCtxKey).(map[string]types.DateTime)
+	if !ok {
+		lastViews = map[string]types.DateTime{}
+		if viewer := api.actorId(c); viewer != "" {
+			if views, err := api.dao.FindPblAppLastViews(viewer); err == nil {
+				lastViews = views
+			}
+		}
+		c.Set(lastViewsCtxKey, lastViews)
+	}
+
+	if lastView, ok := lastViews[app.Id]; ok {
+		return lastView.Time().UnixMilli()
+	}
+	return nil
+}
+
 func (api *openblocksApi) getCorrectDSL(c echo.Context, app *models.Application) string {
 	path := c.Request().Header.Get("Referer")
+	if !models.AppRoleGreaterOrEqual(api.appRole(c, app), models.AppRoleEditor) {
+		return app.AppDsl
+	}
 	if strings.Contains(path, "/edit") || strings.Contains(path, "/preview") {
 		return app.EditDsl
 	}
@@ -771,8 +1101,6 @@ func (api *openblocksApi) getCorrectDSL(c echo.Context, app *models.Application)
 }
 
 func (api *openblocksApi) createFullAppResponse(c echo.Context, app *models.Application) (map[string]interface{}, error) {
-	isAdm := api.isAdmin(c)
-
 	settings, err := api.dao.GetPblSettings().Clone()
 	if err != nil {
 		return nil, err
@@ -795,14 +1123,48 @@ func (api *openblocksApi) createFullAppResponse(c echo.Context, app *models.Appl
 	}
 
 	return map[string]interface{}{
-		"applicationInfoView": api.createAppListItem(app, isAdm),
+		"applicationInfoView": api.createAppListItem(c, app),
 		"applicationDSL":     dsl,
-		"moduleDSL":          map[string]interface{}{},
+		"moduleDSL":          api.collectModuleDSL(c, dsl, []string{app.Slug}),
 		"orgCommonSettings":  commonSettings,
-		"templateId":         nil,
+		"templateId":         api.templateSlug(app),
 	}, nil
 }
 
+// templateSlug returns the slug of the template the app was created from
+// (nil if none or if the template doesn't exist anymore).
+func (api *openblocksApi) templateSlug(app *models.Application) interface{} {
+	if !app.TemplateId.Valid {
+		return nil
+	}
+
+	template, err := api.dao.FindPblAppById(app.TemplateId.String)
+	if err != nil || template == nil {
+		return nil
+	}
+	return template.Slug
+}
+
+// collectModuleDSL returns the published DSL of every module embedded in dsl
+// (recursively) keyed by the module slug.
+//
+// ancestors holds the slugs of the apps being resolved (see [utils.CollectModuleDsls]).
+// Modules that the current request cannot view are skipped.
+func (api *openblocksApi) collectModuleDSL(c echo.Context, dsl interface{}, ancestors []string) map[string]interface{} {
+	return utils.CollectModuleDsls(dsl, ancestors, func(slug string) (any, bool) {
+		module, err := api.dao.FindPblAppBySlug(slug, dbx.HashExp{"type": models.AppTypeModule})
+		if err != nil || module == nil || module.Status == "RECYCLED" || !api.canViewApp(c, module) {
+			return nil, false
+		}
+
+		var moduleDsl interface{}
+		if err := json.Unmarshal([]byte(module.AppDsl), &moduleDsl); err != nil {
+			return nil, false
+		}
+		return moduleDsl, true
+	})
+}
+
 func (api *openblocksApi) listApps(c echo.Context, onlyRecycled bool, folderId string) ([]*models.Application, error) {
 	query := api.dao.PblAppQuery().OrderBy("updated DESC", "created DESC")
 
@@ -821,15 +1183,8 @@ func (api *openblocksApi) listApps(c echo.Context, onlyRecycled bool, folderId s
 	authRecord := api.getAuthRecord(c)
 
 	if admin == nil && authRecord != nil {
-		groups, _ := api.app.Dao().FindRecordsByFilter(
-			"groups",
-			"users.id ?= \""+authRecord.Id+"\"",
-			"-created", 500, 0,
-		)
-		groupIds := []string{}
-		for _, g := range groups {
-			groupIds = append(groupIds, g.Id)
-		}
+		groupIds := api.userGroupIds(c, authRecord)
+		folderIds, _ := api.dao.FindPblFolderIdsByMember(authRecord.Id, groupIds)
 
 		var filterExpr dbx.Expression = dbx.Or(
 			dbx.HashExp{"public": true},
@@ -847,6 +1202,9 @@ func (api *openblocksApi) listApps(c echo.Context, onlyRecycled bool, folderId s
 				dbx.Like("users", authRecord.Id),
 			)
 		}
+		if len(folderIds) > 0 {
+			filterExpr = dbx.Or(filterExpr, dbx.In("folder", list.ToInterfaceSlice(folderIds)...))
+		}
 		query = query.AndWhere(filterExpr)
 	}
 
@@ -878,39 +1236,21 @@ func (api *openblocksApi) applicationsHome(c echo.Context) error {
 		return errResp(c, 500, "Failed to list apps")
 	}
 
-	folders := []*models.Folder{}
-	api.dao.PblFolderQuery().OrderBy("updated DESC", "created DESC").All(&folders)
-
-	// Build folder list
+	// Build the root folders list, every folder view also holds its subfolders tree
+	folders := api.listFolders()
 	folderViews := []interface{}{}
 	for _, f := range folders {
-		folderApps, _ := api.listApps(c, false, f.Id)
-		subApps := []interface{}{}
-		for _, a := range folderApps {
-			subApps = append(subApps, api.createAppListItem(a, isAdm))
-		}
-		if !isAdm && len(subApps) == 0 {
+		if f.ParentId.String != "" {
 			continue
 		}
-		folderViews = append(folderViews, map[string]interface{}{
-			"orgId":           "ORG_ID",
-			"folderId":        f.Id,
-			"parentFolderId":  nil,
-			"name":            f.Name,
-			"createAt":        f.Created.Time().UnixMilli(),
-			"subFolders":      nil,
-			"subApplications": subApps,
-			"createTime":      f.Created.Time().UnixMilli(),
-			"lastViewTime":    f.Updated.Time().UnixMilli(),
-			"visible":         true,
-			"manageable":      isAdm,
-			"folder":          true,
-		})
+		if view := api.createFolderView(c, f, folders, isAdm, nil); view != nil {
+			folderViews = append(folderViews, view)
+		}
 	}
 
 	appViews := []interface{}{}
 	for _, a := range apps {
-		appViews = append(appViews, api.createAppListItem(a, isAdm))
+		appViews = append(appViews, api.createAppListItem(c, a))
 	}
 
 	var userId, userName, userEmail, userUsername string
@@ -1007,10 +1347,7 @@ func (api *openblocksApi) applicationView(c echo.Context) error {
 		return errResp(c, 404, "Application not found")
 	}
 
-	admin := api.getAdmin(c)
-	authRecord := api.getAuthRecord(c)
-
-	if admin == nil && authRecord == nil && !app.Public {
+	if !api.canViewApp(c, app) {
 		return errResp(c, 401, "Unauthorized")
 	}
 
@@ -1018,6 +1355,8 @@ func (api *openblocksApi) applicationView(c echo.Context) error {
 	if err != nil {
 		return errResp(c, 500, "Failed to build response")
 	}
+
+	api.recordAppView(c, app)
 	return okResp(c, resp)
 }
 
@@ -1031,6 +1370,7 @@ func (api *openblocksApi) applicationCreate(c echo.Context) error {
 		EditingApplicationDSL interface{} `json:"editingApplicationDSL"`
 		ApplicationType       int         `json:"applicationType"`
 		FolderId              string      `json:"folderId"`
+		TemplateId            string      `json:"templateId"`
 	}
 	if err := c.Bind(&body); err != nil {
 		return errResp(c, 400, "Invalid request")
@@ -1047,10 +1387,27 @@ func (api *openblocksApi) applicationCreate(c echo.Context) error {
 	form.Status = "NORMAL"
 	form.FolderId = body.FolderId
 
+	// clone the published DSL of the template
+	if body.TemplateId != "" {
+		template, err := api.dao.FindPblAppBySlug(body.TemplateId, dbx.HashExp{"isTemplate": true})
+		if err != nil || template == nil || template.Status == "RECYCLED" {
+			return errResp(c, 404, "Template not found")
+		}
+
+		if form.Name == "" {
+			form.Name = template.Name
+		}
+		form.AppDsl = template.AppDsl
+		form.EditDsl = template.AppDsl
+		form.Type = template.Type
+		form.TemplateId = template.Id
+	}
+
 	app, err := form.Submit()
 	if err != nil {
 		return errResp(c, 400, err.Error())
 	}
+	api.audit(c, "app.create", appAuditTarget(app), nil, auditApp(app))
 
 	resp, err := api.createFullAppResponse(c, app)
 	if err != nil {
@@ -1059,17 +1416,51 @@ func (api *openblocksApi) applicationCreate(c echo.Context) error {
 	return okResp(c, resp)
 }
 
-func (api *openblocksApi) applicationUpdate(c echo.Context) error {
-	if err := api.requireAdmin(c); err != nil {
+func (api *openblocksApi) applicationCopy(c echo.Context) error {
+	slug := c.PathParam("slug")
+	source, err := api.dao.FindPblAppBySlug(slug, nil)
+	if err != nil || source == nil {
+		return errResp(c, 404, "Application not found")
+	}
+
+	if err := api.requireAppRole(c, source, models.AppRoleEditor); err != nil {
 		return err
 	}
 
+	form := forms.NewApplicationCopy(api.dao, source)
+	if err := c.Bind(form); err != nil {
+		return errResp(c, 400, "Invalid request")
+	}
+
+	// the caller keeps its role on the copy (admins are owners of every app)
+	if authRecord := api.getAuthRecord(c); authRecord != nil && !api.isAdmin(c) {
+		form.SetMember(authRecord.Id, api.appRole(c, source))
+	}
+
+	app, err := form.Submit()
+	if err != nil {
+		return errResp(c, 400, err.Error())
+	}
+	api.audit(c, "app.copy", appAuditTarget(app), map[string]interface{}{"source": source.Slug}, auditApp(app))
+
+	resp, err := api.createFullAppResponse(c, app)
+	if err != nil {
+		return errResp(c, 500, "Failed to build response")
+	}
+	return okResp(c, resp)
+}
+
+func (api *openblocksApi) applicationUpdate(c echo.Context) error {
 	slug := c.PathParam("slug")
 	app, err := api.dao.FindPblAppBySlug(slug, nil)
 	if err != nil || app == nil {
 		return errResp(c, 404, "Application not found")
 	}
 
+	if err := api.requireAppRole(c, app, models.AppRoleEditor); err != nil {
+		return err
+	}
+
 	var body struct {
 		Name                  string      `json:"name"`
 		EditingApplicationDSL interface{} `json:"editingApplicationDSL"`
@@ -1079,6 +1470,8 @@ func (api *openblocksApi) applicationUpdate(c echo.Context) error {
 		return errResp(c, 400, "Invalid request")
 	}
 
+	before := auditApp(app)
+
 	form := forms.NewApplicationUpsert(api.dao, app)
 	if body.Name != "" {
 		form.Name = body.Name
@@ -1096,6 +1489,11 @@ func (api *openblocksApi) applicationUpdate(c echo.Context) error {
 		return errResp(c, 400, err.Error())
 	}
 
+	// the dsl autosaves are not audited, only the app renames/type changes
+	if after := auditApp(updated); !reflect.DeepEqual(before, after) {
+		api.audit(c, "app.update", appAuditTarget(updated), before, after)
+	}
+
 	resp, err := api.createFullAppResponse(c, updated)
 	if err != nil {
 		return errResp(c, 500, "Failed to build response")
@@ -1117,32 +1515,53 @@ func (api *openblocksApi) applicationDelete(c echo.Context) error {
 	if err := api.dao.DeletePblApp(app); err != nil {
 		return errResp(c, 400, err.Error())
 	}
+	api.audit(c, "app.delete", appAuditTarget(app), auditApp(app), nil)
 	return okResp(c, true)
 }
 
 func (api *openblocksApi) applicationPublish(c echo.Context) error {
-	if err := api.requireAdmin(c); err != nil {
-		return err
-	}
-
 	slug := c.PathParam("slug")
 	app, err := api.dao.FindPblAppBySlug(slug, nil)
 	if err != nil || app == nil {
 		return errResp(c, 404, "Application not found")
 	}
 
-	form := forms.NewApplicationUpsert(api.dao, app)
-	form.AppDsl = app.EditDsl
+	if err := api.requireAppRole(c, app, models.AppRoleEditor); err != nil {
+		return err
+	}
 
-	updated, err := form.Submit()
+	// publishing records a release, which goes live once approved by a reviewer
+	form := forms.NewReleaseRequest(api.dao, app, api.actorId(c))
+	if err := c.Bind(form); err != nil {
+		return errResp(c, 400, "Invalid request")
+	}
+
+	release, err := form.Submit()
 	if err != nil {
 		return errResp(c, 400, err.Error())
 	}
 
+	// the reviewers' own releases are approved right away
+	if models.AppRoleGreaterOrEqual(api.appRole(c, app), models.AppRoleReviewer) {
+		reviewForm := forms.NewReleaseReview(api.dao, release, api.actorId(c))
+		reviewForm.Approve = true
+		if _, err := reviewForm.Submit(); err != nil {
+			return errResp(c, 400, err.Error())
+		}
+	}
+
+	updated, err := api.dao.FindPblAppById(app.Id)
+	if err != nil {
+		return errResp(c, 404, "Application not found")
+	}
+
 	resp, err := api.createFullAppResponse(c, updated)
 	if err != nil {
 		return errResp(c, 500, "Failed to build response")
 	}
+	resp["release"] = api.createReleaseItem(updated, release)
+
+	api.audit(c, "app.publish", appAuditTarget(updated), nil, auditRelease(release))
 	return okResp(c, resp)
 }
 
@@ -1156,10 +1575,9 @@ func (api *openblocksApi) applicationsList(c echo.Context) error {
 		return errResp(c, 500, "Failed to list apps")
 	}
 
-	isAdm := api.isAdmin(c)
 	result := []interface{}{}
 	for _, a := range apps {
-		result = append(result, api.createAppListItem(a, isAdm))
+		result = append(result, api.createAppListItem(c, a))
 	}
 	return okResp(c, result)
 }
@@ -1176,7 +1594,7 @@ func (api *openblocksApi) applicationsRecycleList(c echo.Context) error {
 
 	result := []interface{}{}
 	for _, a := range apps {
-		result = append(result, api.createAppListItem(a, true))
+		result = append(result, api.createAppListItem(c, a))
 	}
 	return okResp(c, result)
 }
@@ -1192,11 +1610,15 @@ func (api *openblocksApi) applicationRecycle(c echo.Context) error {
 		return errResp(c, 404, "Application not found")
 	}
 
+	before := auditApp(app)
+
 	form := forms.NewApplicationUpsert(api.dao, app)
 	form.Status = "RECYCLED"
-	if _, err := form.Submit(); err != nil {
+	updated, err := form.Submit()
+	if err != nil {
 		return errResp(c, 400, err.Error())
 	}
+	api.audit(c, "app.recycle", appAuditTarget(updated), before, auditApp(updated))
 	return okResp(c, true)
 }
 
@@ -1211,11 +1633,15 @@ func (api *openblocksApi) applicationRestore(c echo.Context) error {
 		return errResp(c, 404, "Application not found")
 	}
 
+	before := auditApp(app)
+
 	form := forms.NewApplicationUpsert(api.dao, app)
 	form.Status = "NORMAL"
-	if _, err := form.Submit(); err != nil {
+	updated, err := form.Submit()
+	if err != nil {
 		return errResp(c, 400, err.Error())
 	}
+	api.audit(c, "app.restore", appAuditTarget(updated), before, auditApp(updated))
 	return okResp(c, true)
 }
 
@@ -1237,18 +1663,52 @@ func (api *openblocksApi) applicationPublicToAll(c echo.Context) error {
 		return errResp(c, 400, "Invalid request")
 	}
 
+	before := auditApp(app)
+
 	form := forms.NewApplicationUpsert(api.dao, app)
 	form.Public = body.PublicToAll
 	updated, err := form.Submit()
 	if err != nil {
 		return errResp(c, 400, err.Error())
 	}
+	api.audit(c, "app.public", appAuditTarget(updated), before, auditApp(updated))
 	return okResp(c, updated.Public)
 }
 
-// --- Permissions ---
+func (api *openblocksApi) applicationTemplatesList(c echo.Context) error {
+	if err := api.requireAuth(c); err != nil {
+		return err
+	}
 
-func (api *openblocksApi) applicationPermissionsGet(c echo.Context) error {
+	query := api.dao.PblAppQuery().
+		AndWhere(dbx.HashExp{"isTemplate": true}).
+		AndWhere(dbx.NewExp("status != 'RECYCLED'")).
+		OrderBy("name ASC")
+
+	// without the folderId param the templates of all folders are listed
+	if folderId, ok := c.QueryParams()["folderId"]; ok {
+		if folderId[0] != "" {
+			query = query.AndWhere(dbx.HashExp{"folder": folderId[0]})
+		} else {
+			query = query.AndWhere(dbx.Or(dbx.HashExp{"folder": ""}, dbx.HashExp{"folder": nil}))
+		}
+	}
+
+	templates := []*models.Application{}
+	if err := query.All(&templates); err != nil {
+		return errResp(c, 500, "Failed to list templates")
+	}
+
+	result := []interface{}{}
+	for _, t := range templates {
+		if api.canViewApp(c, t) {
+			result = append(result, api.createAppListItem(c, t))
+		}
+	}
+	return okResp(c, result)
+}
+
+func (api *openblocksApi) applicationTemplate(c echo.Context) error {
 	if err := api.requireAdmin(c); err != nil {
 		return err
 	}
@@ -1259,24 +1719,56 @@ func (api *openblocksApi) applicationPermissionsGet(c echo.Context) error {
 		return errResp(c, 404, "Application not found")
 	}
 
-	settings, _ := api.dao.GetPblSettings().Clone()
+	var body struct {
+		IsTemplate bool `json:"isTemplate"`
+	}
+	if err := c.Bind(&body); err != nil {
+		return errResp(c, 400, "Invalid request")
+	}
 
-	permissions := []interface{}{}
-	if app.AllUsers {
-		permissions = append(permissions, map[string]interface{}{
-			"permissionId": "all_users|GROUP",
-			"type":         "GROUP",
-			"id":           "all_users",
-			"avatar":       "",
-			"name":         "All Users",
-			"role":         "viewer",
-		})
+	before := auditApp(app)
+
+	form := forms.NewApplicationUpsert(api.dao, app)
+	form.IsTemplate = body.IsTemplate
+	updated, err := form.Submit()
+	if err != nil {
+		return errResp(c, 400, err.Error())
 	}
+	api.audit(c, "app.template", appAuditTarget(updated), before, auditApp(updated))
+	return okResp(c, updated.IsTemplate)
+}
 
-	for _, gId := range app.Groups {
-		gName := gId
-		rec, err := api.app.Dao().FindRecordById("groups", gId)
-		if err == nil {
+// --- Permissions ---
+
+func (api *openblocksApi) applicationPermissionsGet(c echo.Context) error {
+	slug := c.PathParam("slug")
+	app, err := api.dao.FindPblAppBySlug(slug, nil)
+	if err != nil || app == nil {
+		return errResp(c, 404, "Application not found")
+	}
+
+	if err := api.requireAppRole(c, app, models.AppRoleOwner); err != nil {
+		return err
+	}
+
+	settings, _ := api.dao.GetPblSettings().Clone()
+
+	permissions := []interface{}{}
+	if app.AllUsers {
+		permissions = append(permissions, map[string]interface{}{
+			"permissionId": "all_users|GROUP",
+			"type":         "GROUP",
+			"id":           "all_users",
+			"avatar":       "",
+			"name":         "All Users",
+			"role":         app.MemberRole("all_users|GROUP"),
+		})
+	}
+
+	for _, gId := range app.Groups {
+		gName := gId
+		rec, err := api.app.Dao().FindRecordById("groups", gId)
+		if err == nil {
 			gName = rec.GetString("name")
 		}
 		permissions = append(permissions, map[string]interface{}{
@@ -1284,7 +1776,7 @@ func (api *openblocksApi) applicationPermissionsGet(c echo.Context) error {
 			"type":         "GROUP",
 			"id":           gId,
 			"name":         gName,
-			"role":         "viewer",
+			"role":         app.MemberRole(gId + "|GROUP"),
 		})
 	}
 
@@ -1308,7 +1800,7 @@ func (api *openblocksApi) applicationPermissionsGet(c echo.Context) error {
 			"id":           uId,
 			"avatar":       uAvatar,
 			"name":         uName,
-			"role":         "viewer",
+			"role":         app.MemberRole(uId + "|USER"),
 		})
 	}
 
@@ -1333,34 +1825,46 @@ func (api *openblocksApi) applicationPermissionsGet(c echo.Context) error {
 }
 
 func (api *openblocksApi) applicationPermissionsUpdate(c echo.Context) error {
-	if err := api.requireAdmin(c); err != nil {
-		return err
-	}
-
 	slug := c.PathParam("slug")
 	app, err := api.dao.FindPblAppBySlug(slug, nil)
 	if err != nil || app == nil {
 		return errResp(c, 404, "Application not found")
 	}
 
+	if err := api.requireAppRole(c, app, models.AppRoleOwner); err != nil {
+		return err
+	}
+
 	var body struct {
+		Role     string   `json:"role"`
 		UserIds  []string `json:"userIds"`
 		GroupIds []string `json:"groupIds"`
 	}
 	if err := c.Bind(&body); err != nil {
 		return errResp(c, 400, "Invalid request")
 	}
+	if body.Role == "" {
+		body.Role = models.AppRoleViewer
+	}
+
+	before := auditApp(app)
 
 	form := forms.NewApplicationUpsert(api.dao, app)
 	newUsers := append([]string{}, app.Users...)
 	newGroups := append([]string{}, app.Groups...)
+	newRoles := maps.Clone(app.Roles)
+	if newRoles == nil {
+		newRoles = map[string]string{}
+	}
 
 	for _, uid := range body.UserIds {
 		if !slices.Contains(newUsers, uid) {
 			newUsers = append(newUsers, uid)
 		}
+		newRoles[uid+"|USER"] = body.Role
 	}
 	for _, gid := range body.GroupIds {
+		newRoles[gid+"|GROUP"] = body.Role
 		if gid == "all_users" {
 			form.AllUsers = true
 			continue
@@ -1371,18 +1875,58 @@ func (api *openblocksApi) applicationPermissionsUpdate(c echo.Context) error {
 	}
 	form.Users = newUsers
 	form.Groups = newGroups
+	form.Roles = newRoles
 
-	if _, err := form.Submit(); err != nil {
+	updated, err := form.Submit()
+	if err != nil {
 		return errResp(c, 400, err.Error())
 	}
+	api.audit(c, "app.permissions.add", appAuditTarget(updated), before, auditApp(updated))
 	return okResp(c, true)
 }
 
-func (api *openblocksApi) applicationPermissionsDelete(c echo.Context) error {
-	if err := api.requireAdmin(c); err != nil {
+func (api *openblocksApi) applicationPermissionsRoleUpdate(c echo.Context) error {
+	slug := c.PathParam("slug")
+	permId := c.PathParam("permId")
+
+	app, err := api.dao.FindPblAppBySlug(slug, nil)
+	if err != nil || app == nil {
+		return errResp(c, 404, "Application not found")
+	}
+
+	if err := api.requireAppRole(c, app, models.AppRoleOwner); err != nil {
 		return err
 	}
 
+	if !app.HasMember(permId) {
+		return errResp(c, 400, "The user or group is not a member of the application")
+	}
+
+	var body struct {
+		Role string `json:"role"`
+	}
+	if err := c.Bind(&body); err != nil {
+		return errResp(c, 400, "Invalid request")
+	}
+
+	before := auditApp(app)
+
+	form := forms.NewApplicationUpsert(api.dao, app)
+	form.Roles = maps.Clone(app.Roles)
+	if form.Roles == nil {
+		form.Roles = map[string]string{}
+	}
+	form.Roles[permId] = body.Role
+
+	updated, err := form.Submit()
+	if err != nil {
+		return errResp(c, 400, err.Error())
+	}
+	api.audit(c, "app.permissions.update", appAuditTarget(updated), before, auditApp(updated))
+	return okResp(c, true)
+}
+
+func (api *openblocksApi) applicationPermissionsDelete(c echo.Context) error {
 	slug := c.PathParam("slug")
 	permId := c.PathParam("permId")
 
@@ -1391,7 +1935,15 @@ func (api *openblocksApi) applicationPermissionsDelete(c echo.Context) error {
 		return errResp(c, 404, "Application not found")
 	}
 
+	if err := api.requireAppRole(c, app, models.AppRoleOwner); err != nil {
+		return err
+	}
+
+	before := auditApp(app)
+
 	form := forms.NewApplicationUpsert(api.dao, app)
+	form.Roles = maps.Clone(app.Roles)
+	delete(form.Roles, permId)
 
 	if permId == "all_users|GROUP" {
 		form.AllUsers = false
@@ -1420,12 +1972,64 @@ func (api *openblocksApi) applicationPermissionsDelete(c echo.Context) error {
 		}
 	}
 
-	if _, err := form.Submit(); err != nil {
+	updated, err := form.Submit()
+	if err != nil {
 		return errResp(c, 400, err.Error())
 	}
+	api.audit(c, "app.permissions.remove", appAuditTarget(updated), before, auditApp(updated))
 	return okResp(c, true)
 }
 
+// --- Folder helpers ---
+
+func (api *openblocksApi) listFolders() []*models.Folder {
+	folders := []*models.Folder{}
+	api.dao.PblFolderQuery().OrderBy("updated DESC", "created DESC").All(&folders)
+	return folders
+}
+
+// createFolderView builds the folder view with its whole subfolders tree.
+//
+// Returns nil when a non admin user cannot see any app inside the folder tree.
+func (api *openblocksApi) createFolderView(c echo.Context, f *models.Folder, folders []*models.Folder, isAdm bool, ancestors []string) map[string]interface{} {
+	ancestors = append(slices.Clone(ancestors), f.Id)
+
+	subFolders := []interface{}{}
+	for _, sub := range folders {
+		if sub.ParentId.String != f.Id || slices.Contains(ancestors, sub.Id) {
+			continue
+		}
+		if view := api.createFolderView(c, sub, folders, isAdm, ancestors); view != nil {
+			subFolders = append(subFolders, view)
+		}
+	}
+
+	folderApps, _ := api.listApps(c, false, f.Id)
+	subApps := []interface{}{}
+	for _, a := range folderApps {
+		subApps = append(subApps, api.createAppListItem(c, a))
+	}
+
+	if !isAdm && len(subApps) == 0 && len(subFolders) == 0 {
+		return nil
+	}
+
+	return map[string]interface{}{
+		"orgId":           "ORG_ID",
+		"folderId":        f.Id,
+		"parentFolderId":  f.ParentId,
+		"name":            f.Name,
+		"createAt":        f.Created.Time().UnixMilli(),
+		"subFolders":      subFolders,
+		"subApplications": subApps,
+		"createTime":      f.Created.Time().UnixMilli(),
+		"lastViewTime":    f.Updated.Time().UnixMilli(),
+		"visible":         true,
+		"manageable":      isAdm,
+		"folder":          true,
+	}
+}
+
 // --- Folder routes ---
 
 func (api *openblocksApi) foldersElements(c echo.Context) error {
@@ -1438,39 +2042,19 @@ func (api *openblocksApi) foldersElements(c echo.Context) error {
 
 	result := []interface{}{}
 
-	if folderId == "" {
-		folders := []*models.Folder{}
-		api.dao.PblFolderQuery().OrderBy("updated DESC", "created DESC").All(&folders)
-
-		for _, f := range folders {
-			folderApps, _ := api.listApps(c, false, f.Id)
-			subApps := []interface{}{}
-			for _, a := range folderApps {
-				subApps = append(subApps, api.createAppListItem(a, isAdm))
-			}
-			if !isAdm && len(subApps) == 0 {
-				continue
-			}
-			result = append(result, map[string]interface{}{
-				"orgId":           "ORG_ID",
-				"folderId":        f.Id,
-				"parentFolderId":  nil,
-				"name":            f.Name,
-				"createAt":        f.Created.Time().UnixMilli(),
-				"subFolders":      nil,
-				"subApplications": subApps,
-				"createTime":      f.Created.Time().UnixMilli(),
-				"lastViewTime":    f.Updated.Time().UnixMilli(),
-				"visible":         true,
-				"manageable":      isAdm,
-				"folder":          true,
-			})
+	folders := api.listFolders()
+	for _, f := range folders {
+		if f.ParentId.String != folderId {
+			continue
+		}
+		if view := api.createFolderView(c, f, folders, isAdm, nil); view != nil {
+			result = append(result, view)
 		}
 	}
 
 	apps, _ := api.listApps(c, false, folderId)
 	for _, a := range apps {
-		result = append(result, api.createAppListItem(a, isAdm))
+		result = append(result, api.createAppListItem(c, a))
 	}
 
 	return okResp(c, result)
@@ -1482,7 +2066,8 @@ func (api *openblocksApi) foldersCreate(c echo.Context) error {
 	}
 
 	var body struct {
-		Name string `json:"name"`
+		Name           string `json:"name"`
+		ParentFolderId string `json:"parentFolderId"`
 	}
 	if err := c.Bind(&body); err != nil {
 		return errResp(c, 400, "Invalid request")
@@ -1491,26 +2076,15 @@ func (api *openblocksApi) foldersCreate(c echo.Context) error {
 	folder := &models.Folder{}
 	form := forms.NewFolderUpsert(api.dao, folder)
 	form.Name = body.Name
+	form.ParentId = body.ParentFolderId
 
 	created, err := form.Submit()
 	if err != nil {
 		return errResp(c, 400, err.Error())
 	}
+	api.audit(c, "folder.create", folderAuditTarget(created), nil, auditFolder(created))
 
-	return okResp(c, map[string]interface{}{
-		"orgId":           "ORG_ID",
-		"folderId":        created.Id,
-		"parentFolderId":  nil,
-		"name":            created.Name,
-		"createAt":        created.Created.Time().UnixMilli(),
-		"subFolders":      nil,
-		"subApplications": []interface{}{},
-		"createTime":      created.Created.Time().UnixMilli(),
-		"lastViewTime":    created.Updated.Time().UnixMilli(),
-		"visible":         true,
-		"manageable":      true,
-		"folder":          true,
-	})
+	return okResp(c, api.createFolderView(c, created, nil, true, nil))
 }
 
 func (api *openblocksApi) foldersUpdate(c echo.Context) error {
@@ -1531,6 +2105,8 @@ func (api *openblocksApi) foldersUpdate(c echo.Context) error {
 		return errResp(c, 404, "Folder not found")
 	}
 
+	before := auditFolder(folder)
+
 	form := forms.NewFolderUpsert(api.dao, folder)
 	form.Name = body.Name
 
@@ -1538,27 +2114,9 @@ func (api *openblocksApi) foldersUpdate(c echo.Context) error {
 	if err != nil {
 		return errResp(c, 400, err.Error())
 	}
+	api.audit(c, "folder.update", folderAuditTarget(updated), before, auditFolder(updated))
 
-	folderApps, _ := api.listApps(c, false, updated.Id)
-	subApps := []interface{}{}
-	for _, a := range folderApps {
-		subApps = append(subApps, api.createAppListItem(a, true))
-	}
-
-	return okResp(c, map[string]interface{}{
-		"orgId":           "ORG_ID",
-		"folderId":        updated.Id,
-		"parentFolderId":  nil,
-		"name":            updated.Name,
-		"createAt":        updated.Created.Time().UnixMilli(),
-		"subFolders":      nil,
-		"subApplications": subApps,
-		"createTime":      updated.Created.Time().UnixMilli(),
-		"lastViewTime":    updated.Updated.Time().UnixMilli(),
-		"visible":         true,
-		"manageable":      true,
-		"folder":          true,
-	})
+	return okResp(c, api.createFolderView(c, updated, api.listFolders(), true, nil))
 }
 
 func (api *openblocksApi) foldersMove(c echo.Context) error {
@@ -1571,14 +2129,33 @@ func (api *openblocksApi) foldersMove(c echo.Context) error {
 
 	app, err := api.dao.FindPblAppBySlug(appSlug, nil)
 	if err != nil || app == nil {
-		return errResp(c, 404, "Application not found")
+		// the source may be a folder being moved into another one
+		folder, err := api.dao.FindPblFolderById(appSlug)
+		if err != nil || folder == nil {
+			return errResp(c, 404, "Application not found")
+		}
+
+		before := auditFolder(folder)
+
+		form := forms.NewFolderUpsert(api.dao, folder)
+		form.ParentId = targetFolderId
+		updated, err := form.Submit()
+		if err != nil {
+			return errResp(c, 400, err.Error())
+		}
+		api.audit(c, "folder.move", folderAuditTarget(updated), before, auditFolder(updated))
+		return okResp(c, nil)
 	}
 
+	before := auditApp(app)
+
 	form := forms.NewApplicationUpsert(api.dao, app)
 	form.FolderId = targetFolderId
-	if _, err := form.Submit(); err != nil {
+	updated, err := form.Submit()
+	if err != nil {
 		return errResp(c, 400, err.Error())
 	}
+	api.audit(c, "app.move", appAuditTarget(updated), before, auditApp(updated))
 	return okResp(c, nil)
 }
 
@@ -1596,6 +2173,7 @@ func (api *openblocksApi) foldersDelete(c echo.Context) error {
 	if err := api.dao.DeletePblFolder(folder); err != nil {
 		return errResp(c, 400, err.Error())
 	}
+	api.audit(c, "folder.delete", folderAuditTarget(folder), auditFolder(folder), nil)
 	return okResp(c, nil)
 }
 
@@ -1645,13 +2223,18 @@ func (api *openblocksApi) groupsList(c echo.Context) error {
 // --- Snapshots ---
 
 func (api *openblocksApi) snapshotView(c echo.Context) error {
-	if err := api.requireAdmin(c); err != nil {
+	app, err := api.dao.FindPblAppBySlug(c.PathParam("appSlug"), nil)
+	if err != nil || app == nil {
+		return errResp(c, 404, "Application not found")
+	}
+
+	if err := api.requireAppRole(c, app, models.AppRoleEditor); err != nil {
 		return err
 	}
 
 	id := c.PathParam("id")
 	snapshot, err := api.dao.FindPblSnapshotById(id)
-	if err != nil || snapshot == nil {
+	if err != nil || snapshot == nil || snapshot.AppId != app.Id {
 		return errResp(c, 404, "Snapshot not found")
 	}
 
@@ -1660,21 +2243,21 @@ func (api *openblocksApi) snapshotView(c echo.Context) error {
 
 	return okResp(c, map[string]interface{}{
 		"applicationsDsl": dsl,
-		"moduleDSL":       map[string]interface{}{},
+		"moduleDSL":       api.collectModuleDSL(c, dsl, []string{c.PathParam("appSlug")}),
 	})
 }
 
 func (api *openblocksApi) snapshotList(c echo.Context) error {
-	if err := api.requireAdmin(c); err != nil {
-		return err
-	}
-
 	appSlug := c.PathParam("appSlug")
 	app, err := api.dao.FindPblAppBySlug(appSlug, nil)
 	if err != nil || app == nil {
 		return errResp(c, 404, "Application not found")
 	}
 
+	if err := api.requireAppRole(c, app, models.AppRoleEditor); err != nil {
+		return err
+	}
+
 	page, _ := strconv.Atoi(c.QueryParam("page"))
 	size, _ := strconv.Atoi(c.QueryParam("size"))
 	if page < 1 {
@@ -1716,104 +2299,946 @@ func (api *openblocksApi) snapshotList(c echo.Context) error {
 	})
 }
 
-func (api *openblocksApi) snapshotCreate(c echo.Context) error {
-	if err := api.requireAdmin(c); err != nil {
-		return err
-	}
+// --- Releases ---
 
-	var body struct {
-		ApplicationId string      `json:"applicationId"`
-		Context       interface{} `json:"context"`
-		Dsl           interface{} `json:"dsl"`
+// actorId returns the id of the current admin or user.
+func (api *openblocksApi) actorId(c echo.Context) string {
+	if admin := api.getAdmin(c); admin != nil {
+		return admin.Id
 	}
-	if err := c.Bind(&body); err != nil {
-		return errResp(c, 400, "Invalid request")
+	if authRecord := api.getAuthRecord(c); authRecord != nil {
+		return authRecord.Id
 	}
+	return ""
+}
 
-	app, err := api.dao.FindPblAppBySlug(body.ApplicationId, nil)
-	if err != nil || app == nil {
-		return errResp(c, 404, "Application not found")
+// actorInfo resolves the name of a user or admin id (see actorId).
+func (api *openblocksApi) actorInfo(id string) interface{} {
+	if id == "" {
+		return nil
 	}
 
-	contextBytes, _ := json.Marshal(body.Context)
-	dslBytes, _ := json.Marshal(body.Dsl)
-
-	snapshot := &models.Snapshot{
-		AppId:   app.Id,
-		Context: string(contextBytes),
-		Dsl:     string(dslBytes),
+	name := id
+	if rec, err := api.app.Dao().FindRecordById("users", id); err == nil {
+		if n := rec.GetString("name"); n != "" && n != "NONAME" {
+			name = n
+		} else {
+			name = rec.Username()
+		}
+	} else if admin, err := api.app.Dao().FindAdminById(id); err == nil {
+		name = admin.Email
 	}
-	snapshot.MarkAsNew()
-	snapshot.SetId(utils.GenerateId())
-	snapshot.Created.Scan(time.Now().UTC())
-	snapshot.Updated.Scan(time.Now().UTC())
 
-	if err := api.dao.SavePblSnapshot(snapshot); err != nil {
-		return errResp(c, 400, err.Error())
-	}
-	return okResp(c, true)
+	return map[string]interface{}{"id": id, "name": name}
 }
 
-// --- Configs ---
-
-func (api *openblocksApi) configsView(c echo.Context) error {
-	settings, err := api.dao.GetPblSettings().Clone()
-	if err != nil {
-		return errResp(c, 500, "Failed to load settings")
+func (api *openblocksApi) createReleaseItem(app *models.Application, release *models.Release) map[string]interface{} {
+	var scheduledAt, appliedAt interface{}
+	if !release.ScheduledAt.IsZero() {
+		scheduledAt = release.ScheduledAt.Time().UnixMilli()
+	}
+	if !release.AppliedAt.IsZero() {
+		appliedAt = release.AppliedAt.Time().UnixMilli()
 	}
 
-	return okResp(c, map[string]interface{}{
-		"authConfigs":   api.buildAuthConfigs(),
-		"workspaceMode": "ENTERPRISE",
-		"selfDomain":    false,
-		"cookieName":    "TOKEN",
-		"cloudHosting":  false,
-		"featureFlag": map[string]interface{}{
-			"enableCustomBranding": true,
-		},
-		"branding": map[string]interface{}{
-			"logo":        settings.LogoUrl,
-			"favicon":     settings.IconUrl,
-			"brandName":   settings.Name,
-			"headerColor": settings.HeaderColor,
-		},
-	})
+	return map[string]interface{}{
+		"releaseId":   release.Id,
+		"version":     release.Version,
+		"notes":       release.Notes,
+		"status":      release.Status,
+		"live":        release.Id == app.ReleaseId,
+		"requestedBy": api.actorInfo(release.RequestedBy),
+		"reviewedBy":  api.actorInfo(release.ReviewedBy),
+		"scheduledAt": scheduledAt,
+		"appliedAt":   appliedAt,
+		"createTime":  release.Created.Time().UnixMilli(),
+	}
 }
 
-func (api *openblocksApi) configsUpdate(c echo.Context) error {
-	if err := api.requireAdmin(c); err != nil {
+func (api *openblocksApi) releasesList(c echo.Context) error {
+	slug := c.PathParam("slug")
+	app, err := api.dao.FindPblAppBySlug(slug, nil)
+	if err != nil || app == nil {
+		return errResp(c, 404, "Application not found")
+	}
+
+	if err := api.requireAppRole(c, app, models.AppRoleEditor); err != nil {
 		return err
 	}
 
-	var body struct {
-		Branding *struct {
-			BrandName   string `json:"brandName"`
-			HeaderColor string `json:"headerColor"`
-			Favicon     string `json:"favicon"`
-			Logo        string `json:"logo"`
-		} `json:"branding"`
-		Auths *models.Auths `json:"auths"`
+	query := api.dao.PblReleaseQuery().
+		AndWhere(dbx.HashExp{"app": app.Id}).
+		OrderBy("created DESC")
+	if status := c.QueryParam("status"); status != "" {
+		query = query.AndWhere(dbx.HashExp{"status": status})
 	}
-	if err := c.Bind(&body); err != nil {
-		return errResp(c, 400, "Invalid request")
+
+	releases := []*models.Release{}
+	if err := query.All(&releases); err != nil {
+		return errResp(c, 500, "Failed to list releases")
 	}
 
-	settingsForm := forms.NewSettingsUpsert(api.dao)
-	if body.Branding != nil {
-		settingsForm.Name = body.Branding.BrandName
-		settingsForm.HeaderColor = body.Branding.HeaderColor
-		settingsForm.IconUrl = body.Branding.Favicon
-		settingsForm.LogoUrl = body.Branding.Logo
+	result := []interface{}{}
+	for _, r := range releases {
+		result = append(result, api.createReleaseItem(app, r))
 	}
-	if body.Auths != nil {
-		settingsForm.Auths = *body.Auths
+	return okResp(c, result)
+}
+
+func (api *openblocksApi) releaseApprove(c echo.Context) error {
+	return api.reviewRelease(c, true)
+}
+
+func (api *openblocksApi) releaseReject(c echo.Context) error {
+	return api.reviewRelease(c, false)
+}
+
+func (api *openblocksApi) reviewRelease(c echo.Context, approve bool) error {
+	slug := c.PathParam("slug")
+	app, err := api.dao.FindPblAppBySlug(slug, nil)
+	if err != nil || app == nil {
+		return errResp(c, 404, "Application not found")
 	}
 
-	if err := settingsForm.Submit(); err != nil {
-		return errResp(c, 400, err.Error())
+	if err := api.requireAppRole(c, app, models.AppRoleReviewer); err != nil {
+		return err
+	}
+
+	release, err := api.dao.FindPblReleaseById(c.PathParam("id"))
+	if err != nil || release == nil || release.AppId != app.Id {
+		return errResp(c, 404, "Release not found")
+	}
+
+	before := auditRelease(release)
+
+	form := forms.NewReleaseReview(api.dao, release, api.actorId(c))
+	form.Approve = approve
+	if _, err := form.Submit(); err != nil {
+		return errResp(c, 400, err.Error())
+	}
+
+	action := "release.reject"
+	if approve {
+		action = "release.approve"
+	}
+	api.audit(c, action, appAuditTarget(app), before, auditRelease(release))
+
+	// the app live release may have changed
+	if updated, err := api.dao.FindPblAppById(app.Id); err == nil {
+		app = updated
+	}
+
+	return okResp(c, api.createReleaseItem(app, release))
+}
+
+func (api *openblocksApi) releaseView(c echo.Context) error {
+	slug := c.PathParam("slug")
+	app, err := api.dao.FindPblAppBySlug(slug, nil)
+	if err != nil || app == nil {
+		return errResp(c, 404, "Application not found")
+	}
+
+	if err := api.requireAppRole(c, app, models.AppRoleEditor); err != nil {
+		return err
+	}
+
+	release, err := api.dao.FindPblReleaseById(c.PathParam("id"))
+	if err != nil || release == nil || release.AppId != app.Id {
+		return errResp(c, 404, "Release not found")
+	}
+
+	var dsl interface{}
+	json.Unmarshal([]byte(release.Dsl), &dsl)
+
+	resp := api.createReleaseItem(app, release)
+	resp["applicationDSL"] = dsl
+	return okResp(c, resp)
+}
+
+func (api *openblocksApi) releaseRollback(c echo.Context) error {
+	slug := c.PathParam("slug")
+	app, err := api.dao.FindPblAppBySlug(slug, nil)
+	if err != nil || app == nil {
+		return errResp(c, 404, "Application not found")
+	}
+
+	if err := api.requireAppRole(c, app, models.AppRoleReviewer); err != nil {
+		return err
+	}
+
+	release, err := api.dao.FindPblReleaseById(c.PathParam("id"))
+	if err != nil || release == nil || release.AppId != app.Id {
+		return errResp(c, 404, "Release not found")
+	}
+
+	before := map[string]interface{}{"release": app.ReleaseId}
+
+	updated, err := forms.NewReleaseRollback(api.dao, app, release).Submit()
+	if err != nil {
+		return errResp(c, 400, err.Error())
+	}
+	api.audit(c, "release.rollback", appAuditTarget(updated), before, auditRelease(release))
+
+	resp, err := api.createFullAppResponse(c, updated)
+	if err != nil {
+		return errResp(c, 500, "Failed to build response")
+	}
+	resp["release"] = api.createReleaseItem(updated, release)
+	return okResp(c, resp)
+}
+
+// findAppVersionDsl returns the parsed DSL of an app version, which is either
+// a snapshot id, "editing" (the current EditDsl) or "published" (the AppDsl).
+func (api *openblocksApi) findAppVersionDsl(app *models.Application, version string) (interface{}, error) {
+	var dslStr string
+	switch version {
+	case "", "editing":
+		dslStr = app.EditDsl
+	case "published":
+		dslStr = app.AppDsl
+	default:
+		snapshot, err := api.dao.FindPblSnapshotById(version)
+		if err != nil {
+			return nil, err
+		}
+		if snapshot.AppId != app.Id {
+			return nil, sql.ErrNoRows
+		}
+		dslStr = snapshot.Dsl
+	}
+
+	var dsl interface{}
+	if dslStr != "" {
+		if err := json.Unmarshal([]byte(dslStr), &dsl); err != nil {
+			return nil, err
+		}
+	}
+	return dsl, nil
+}
+
+func (api *openblocksApi) snapshotDiff(c echo.Context) error {
+	app, err := api.dao.FindPblAppBySlug(c.PathParam("appSlug"), nil)
+	if err != nil || app == nil {
+		return errResp(c, 404, "Application not found")
+	}
+
+	if err := api.requireAppRole(c, app, models.AppRoleEditor); err != nil {
+		return err
+	}
+
+	from := c.QueryParam("from")
+	to := c.QueryParam("to")
+	if from == "" {
+		return errResp(c, 400, "Missing from version")
+	}
+
+	fromDsl, err := api.findAppVersionDsl(app, from)
+	if err != nil {
+		return errResp(c, 404, "Version not found: "+from)
+	}
+	toDsl, err := api.findAppVersionDsl(app, to)
+	if err != nil {
+		return errResp(c, 404, "Version not found: "+to)
+	}
+
+	diff := utils.DiffDsl(fromDsl, toDsl)
+
+	return okResp(c, map[string]interface{}{
+		"from":       from,
+		"to":         cmp.Or(to, "editing"),
+		"identical":  diff.IsEmpty(),
+		"components": diff.Components,
+		"queries":    diff.Queries,
+		"layout":     diff.Layout,
+		"other":      diff.Other,
+	})
+}
+
+func (api *openblocksApi) snapshotCreate(c echo.Context) error {
+	var body struct {
+		ApplicationId string      `json:"applicationId"`
+		Context       interface{} `json:"context"`
+		Dsl           interface{} `json:"dsl"`
+	}
+	if err := c.Bind(&body); err != nil {
+		return errResp(c, 400, "Invalid request")
+	}
+
+	app, err := api.dao.FindPblAppBySlug(body.ApplicationId, nil)
+	if err != nil || app == nil {
+		return errResp(c, 404, "Application not found")
+	}
+
+	if err := api.requireAppRole(c, app, models.AppRoleEditor); err != nil {
+		return err
+	}
+
+	contextBytes, _ := json.Marshal(body.Context)
+	dslBytes, _ := json.Marshal(body.Dsl)
+
+	snapshot := &models.Snapshot{
+		AppId:   app.Id,
+		Context: string(contextBytes),
+		Dsl:     string(dslBytes),
+	}
+	snapshot.MarkAsNew()
+	snapshot.SetId(utils.GenerateId())
+	snapshot.Created.Scan(time.Now().UTC())
+	snapshot.Updated.Scan(time.Now().UTC())
+
+	if err := api.dao.SavePblSnapshot(snapshot); err != nil {
+		return errResp(c, 400, err.Error())
+	}
+	return okResp(c, true)
+}
+
+func (api *openblocksApi) snapshotRestore(c echo.Context) error {
+	app, err := api.dao.FindPblAppBySlug(c.PathParam("appSlug"), nil)
+	if err != nil || app == nil {
+		return errResp(c, 404, "Application not found")
+	}
+
+	if err := api.requireAppRole(c, app, models.AppRoleEditor); err != nil {
+		return err
+	}
+
+	snapshot, err := api.dao.FindPblSnapshotById(c.PathParam("id"))
+	if err != nil || snapshot == nil || snapshot.AppId != app.Id {
+		return errResp(c, 404, "Snapshot not found")
+	}
+
+	form := forms.NewSnapshotRestore(api.dao, app, snapshot, api.actorId(c))
+	if err := c.Bind(form); err != nil {
+		return errResp(c, 400, "Invalid request")
+	}
+
+	updated, backup, release, err := form.Submit()
+	if err != nil {
+		return errResp(c, 400, err.Error())
+	}
+
+	// restoring the published DSL goes through the release approvals,
+	// with the reviewers' own releases approved right away (as on publish)
+	if release != nil && models.AppRoleGreaterOrEqual(api.appRole(c, app), models.AppRoleReviewer) {
+		reviewForm := forms.NewReleaseReview(api.dao, release, api.actorId(c))
+		reviewForm.Approve = true
+		if _, err := reviewForm.Submit(); err != nil {
+			return errResp(c, 400, err.Error())
+		}
+
+		if updated, err = api.dao.FindPblAppById(app.Id); err != nil {
+			return errResp(c, 404, "Application not found")
+		}
+	}
+
+	auditData := map[string]interface{}{
+		"snapshot": snapshot.Id,
+		"backup":   backup.Id,
+	}
+	if release != nil {
+		auditData["release"] = auditRelease(release)
+	}
+	api.audit(c, "snapshot.restore", appAuditTarget(updated), nil, auditData)
+
+	resp, err := api.createFullAppResponse(c, updated)
+	if err != nil {
+		return errResp(c, 500, "Failed to build response")
+	}
+	resp["backupSnapshotId"] = backup.Id
+	if release != nil {
+		resp["release"] = api.createReleaseItem(updated, release)
+	}
+	return okResp(c, resp)
+}
+
+// --- Datasources ---
+
+func (api *openblocksApi) createDatasourceView(ds *models.Datasource, isAdm bool) map[string]interface{} {
+	// configs may hold credentials, so only admins get to see them
+	config := map[string]interface{}{}
+	apps := []string{}
+	if isAdm {
+		json.Unmarshal([]byte(ds.Config), &config)
+		apps = append(apps, ds.Apps...)
+	}
+
+	return map[string]interface{}{
+		"id":               ds.Id,
+		"name":             ds.Name,
+		"type":             ds.Type,
+		"organizationId":   "ORG_ID",
+		"datasourceConfig": config,
+		"apps":             apps,
+		"creationSource":   0,
+		"createTime":       ds.Created.Time().UnixMilli(),
+	}
+}
+
+func (api *openblocksApi) datasourceTypes(c echo.Context) error {
+	if err := api.requireAuth(c); err != nil {
+		return err
+	}
+
+	names := map[string]string{
+		models.DatasourceTypeRestApi:    "REST API",
+		models.DatasourceTypePocketBase: "PocketBase",
+		models.DatasourceTypeSqlite:     "SQLite",
+	}
+
+	result := []interface{}{}
+	for _, t := range models.DatasourceTypes {
+		result = append(result, map[string]interface{}{
+			"id":               t,
+			"name":             names[t],
+			"version":          "",
+			"hasStructureInfo": false,
+		})
+	}
+	return okResp(c, result)
+}
+
+func (api *openblocksApi) listDatasourceInfos(c echo.Context) ([]interface{}, error) {
+	datasources := []*models.Datasource{}
+	if err := api.dao.PblDatasourceQuery().OrderBy("name ASC").All(&datasources); err != nil {
+		return nil, err
+	}
+
+	isAdm := api.isAdmin(c)
+	result := []interface{}{}
+	for _, ds := range datasources {
+		result = append(result, map[string]interface{}{
+			"datasource": api.createDatasourceView(ds, isAdm),
+			"edit":       isAdm,
+		})
+	}
+	return result, nil
+}
+
+func (api *openblocksApi) datasourcesListByApp(c echo.Context) error {
+	app, err := api.dao.FindPblAppBySlug(c.QueryParam("appId"), nil)
+	if err != nil || app == nil {
+		return errResp(c, 404, "Application not found")
+	}
+
+	if !api.canViewApp(c, app) {
+		return errResp(c, 401, "Unauthorized")
+	}
+
+	result, err := api.listDatasourceInfos(c)
+	if err != nil {
+		return errResp(c, 500, "Failed to list datasources")
+	}
+	return okResp(c, result)
+}
+
+func (api *openblocksApi) datasourcesListByOrg(c echo.Context) error {
+	if err := api.requireAdmin(c); err != nil {
+		return err
+	}
+
+	result, err := api.listDatasourceInfos(c)
+	if err != nil {
+		return errResp(c, 500, "Failed to list datasources")
+	}
+	return okResp(c, result)
+}
+
+func (api *openblocksApi) datasourceCreate(c echo.Context) error {
+	if err := api.requireAdmin(c); err != nil {
+		return err
+	}
+
+	var body struct {
+		Name             string      `json:"name"`
+		Type             string      `json:"type"`
+		DatasourceConfig interface{} `json:"datasourceConfig"`
+		Apps             []string    `json:"apps"`
+	}
+	if err := c.Bind(&body); err != nil {
+		return errResp(c, 400, "Invalid request")
+	}
+
+	configBytes, _ := json.Marshal(body.DatasourceConfig)
+
+	form := forms.NewDatasourceUpsert(api.dao, &models.Datasource{})
+	form.Name = body.Name
+	form.Type = body.Type
+	form.Config = string(configBytes)
+	form.Apps = body.Apps
+
+	ds, err := form.Submit()
+	if err != nil {
+		return errResp(c, 400, err.Error())
+	}
+	api.audit(c, "datasource.create", datasourceAuditTarget(ds), nil, auditDatasource(ds))
+	return okResp(c, api.createDatasourceView(ds, true))
+}
+
+func (api *openblocksApi) datasourceUpdate(c echo.Context) error {
+	if err := api.requireAdmin(c); err != nil {
+		return err
+	}
+
+	ds, err := api.dao.FindPblDatasourceById(c.PathParam("id"))
+	if err != nil || ds == nil {
+		return errResp(c, 404, "Datasource not found")
+	}
+
+	var body struct {
+		Name             string      `json:"name"`
+		DatasourceConfig interface{} `json:"datasourceConfig"`
+		Apps             []string    `json:"apps"`
+	}
+	if err := c.Bind(&body); err != nil {
+		return errResp(c, 400, "Invalid request")
+	}
+
+	before := auditDatasource(ds)
+
+	form := forms.NewDatasourceUpsert(api.dao, ds)
+	if body.Name != "" {
+		form.Name = body.Name
+	}
+	if body.DatasourceConfig != nil {
+		configBytes, _ := json.Marshal(body.DatasourceConfig)
+		form.Config = string(configBytes)
+	}
+	if body.Apps != nil {
+		form.Apps = body.Apps
+	}
+
+	updated, err := form.Submit()
+	if err != nil {
+		return errResp(c, 400, err.Error())
+	}
+	api.audit(c, "datasource.update", datasourceAuditTarget(updated), before, auditDatasource(updated))
+	return okResp(c, api.createDatasourceView(updated, true))
+}
+
+func (api *openblocksApi) datasourceDelete(c echo.Context) error {
+	if err := api.requireAdmin(c); err != nil {
+		return err
+	}
+
+	ds, err := api.dao.FindPblDatasourceById(c.PathParam("id"))
+	if err != nil || ds == nil {
+		return errResp(c, 404, "Datasource not found")
+	}
+
+	if err := api.dao.DeletePblDatasource(ds); err != nil {
+		return errResp(c, 400, err.Error())
+	}
+	api.audit(c, "datasource.delete", datasourceAuditTarget(ds), auditDatasource(ds), nil)
+	return okResp(c, true)
+}
+
+// --- Queries ---
+
+func queryResp(c echo.Context, data interface{}, runTime time.Duration, err error) error {
+	if err != nil {
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"code": 500, "message": err.Error(), "success": false, "data": nil,
+			"runTime": runTime.Milliseconds(), "queryCode": "QUERY_EXECUTION_ERROR",
+		})
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"code": 1, "message": "", "success": true, "data": data,
+		"runTime": runTime.Milliseconds(), "queryCode": "OK",
+	})
+}
+
+func (api *openblocksApi) queryExecute(c echo.Context) error {
+	var body struct {
+		ApplicationId  string                 `json:"applicationId"`
+		QueryId        string                 `json:"queryId"`
+		Params         []datasources.Property `json:"params"`
+		ViewMode       bool                   `json:"viewMode"`
+		LibraryQueryId string                 `json:"libraryQueryId"`
+	}
+	if err := c.Bind(&body); err != nil {
+		return errResp(c, 400, "Invalid request")
+	}
+
+	var query *datasources.Query
+
+	// the app whose (editor authored) queries are executed,
+	// nil for the admin authored library queries
+	var app *models.Application
+
+	if body.LibraryQueryId != "" {
+		// running the draft from the query library editor
+		if err := api.requireAdmin(c); err != nil {
+			return err
+		}
+
+		lq, err := api.dao.FindPblLibraryQueryById(body.LibraryQueryId)
+		if err != nil || lq == nil {
+			return errResp(c, 404, "Library query not found")
+		}
+
+		query, err = datasources.ParseLibraryQuery(lq.EditDsl)
+		if err != nil {
+			return errResp(c, 404, "Query not found")
+		}
+	} else {
+		var err error
+		app, err = api.dao.FindPblAppBySlug(body.ApplicationId, nil)
+		if err != nil || app == nil {
+			return errResp(c, 404, "Application not found")
+		}
+
+		if !api.canViewApp(c, app) {
+			return errResp(c, 401, "Unauthorized")
+		}
+
+		// only editors can run the unpublished queries
+		dsl := app.AppDsl
+		if !body.ViewMode {
+			if err := api.requireAppRole(c, app, models.AppRoleEditor); err != nil {
+				return err
+			}
+			dsl = app.EditDsl
+		}
+
+		query, err = datasources.FindQuery(dsl, body.QueryId)
+		if err != nil {
+			return errResp(c, 404, "Query not found")
+		}
+
+		if lqId := query.LibraryQueryId(); lqId != "" {
+			query, err = api.findPublishedLibraryQuery(lqId)
+			if err != nil {
+				return errResp(c, 404, "Library query not found")
+			}
+			app = nil
+		}
+	}
+
+	if query.DatasourceId == "" {
+		return errResp(c, 400, "Query is not bound to a datasource")
+	}
+
+	ds, err := api.dao.FindPblDatasourceById(query.DatasourceId)
+	if err != nil || ds == nil {
+		return errResp(c, 404, "Datasource not found")
+	}
+
+	// any editor can write the app queries, so they only reach
+	// the datasources an admin made available to the app
+	if app != nil && !ds.AllowsApp(app.Id) {
+		return errResp(c, 403, "Datasource not available to this application")
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request().Context(), queryExecuteTimeout)
+	defer cancel()
+
+	start := time.Now()
+	result, err := datasources.Execute(ctx, api.dao, ds, query, datasources.NewParams(body.Params))
+
+	return queryResp(c, result, time.Since(start), err)
+}
+
+// --- Library Queries ---
+
+// findPublishedLibraryQuery returns the published query of the library query,
+// so every app referencing it always runs its latest published version.
+func (api *openblocksApi) findPublishedLibraryQuery(id string) (*datasources.Query, error) {
+	lq, err := api.dao.FindPblLibraryQueryById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !lq.IsPublished() {
+		return nil, datasources.ErrQueryNotFound
+	}
+
+	return datasources.ParseLibraryQuery(lq.PublishedDsl)
+}
+
+func (api *openblocksApi) libraryQueryDatasourceType(lq *models.LibraryQuery) string {
+	query, err := datasources.ParseLibraryQuery(lq.EditDsl)
+	if err != nil {
+		return ""
+	}
+	return query.CompType
+}
+
+func (api *openblocksApi) createLibraryQueryView(lq *models.LibraryQuery) map[string]interface{} {
+	var dsl interface{}
+	json.Unmarshal([]byte(lq.EditDsl), &dsl)
+
+	return map[string]interface{}{
+		"id":              lq.Id,
+		"organizationId":  "ORG_ID",
+		"name":            lq.Name,
+		"libraryQueryDSL": dsl,
+		"creatorName":     "",
+		"createTime":      lq.Created.Time().UnixMilli(),
+	}
+}
+
+func (api *openblocksApi) createLibraryQueryRecordMetas(lq *models.LibraryQuery) []interface{} {
+	if !lq.IsPublished() {
+		return []interface{}{}
+	}
+
+	return []interface{}{map[string]interface{}{
+		"id":             lq.Id,
+		"libraryQueryId": lq.Id,
+		"tag":            lq.Tag,
+		"commitMessage":  lq.CommitMessage,
+		"createTime":     lq.Published.Time().UnixMilli(),
+		"creatorName":    "",
+		"datasourceType": api.libraryQueryDatasourceType(lq),
+	}}
+}
+
+func (api *openblocksApi) libraryQueriesList(c echo.Context) error {
+	if err := api.requireAdmin(c); err != nil {
+		return err
+	}
+
+	libraryQueries := []*models.LibraryQuery{}
+	if err := api.dao.PblLibraryQueryQuery().OrderBy("name ASC").All(&libraryQueries); err != nil {
+		return errResp(c, 500, "Failed to list library queries")
+	}
+
+	result := []interface{}{}
+	for _, lq := range libraryQueries {
+		result = append(result, api.createLibraryQueryView(lq))
+	}
+	return okResp(c, result)
+}
+
+func (api *openblocksApi) libraryQueriesDropDownList(c echo.Context) error {
+	if err := api.requireAuth(c); err != nil {
+		return err
+	}
+
+	libraryQueries := []*models.LibraryQuery{}
+	if err := api.dao.PblLibraryQueryQuery().OrderBy("name ASC").All(&libraryQueries); err != nil {
+		return errResp(c, 500, "Failed to list library queries")
+	}
+
+	result := []interface{}{}
+	for _, lq := range libraryQueries {
+		result = append(result, map[string]interface{}{
+			"libraryQueryMetaView": map[string]interface{}{
+				"id":             lq.Id,
+				"datasourceType": api.libraryQueryDatasourceType(lq),
+				"organizationId": "ORG_ID",
+				"name":           lq.Name,
+				"creatorName":    "",
+				"createTime":     lq.Created.Time().UnixMilli(),
+			},
+			"recordMetaViewList": api.createLibraryQueryRecordMetas(lq),
+		})
+	}
+	return okResp(c, result)
+}
+
+func (api *openblocksApi) libraryQueryCreate(c echo.Context) error {
+	if err := api.requireAdmin(c); err != nil {
+		return err
+	}
+
+	var body struct {
+		Name            string      `json:"name"`
+		LibraryQueryDSL interface{} `json:"libraryQueryDSL"`
+	}
+	if err := c.Bind(&body); err != nil {
+		return errResp(c, 400, "Invalid request")
+	}
+
+	dslBytes, _ := json.Marshal(body.LibraryQueryDSL)
+
+	form := forms.NewLibraryQueryUpsert(api.dao, &models.LibraryQuery{})
+	form.Name = body.Name
+	form.EditDsl = string(dslBytes)
+
+	lq, err := form.Submit()
+	if err != nil {
+		return errResp(c, 400, err.Error())
+	}
+	api.audit(c, "libraryQuery.create", libraryQueryAuditTarget(lq), nil, auditLibraryQuery(lq))
+	return okResp(c, api.createLibraryQueryView(lq))
+}
+
+func (api *openblocksApi) libraryQueryUpdate(c echo.Context) error {
+	if err := api.requireAdmin(c); err != nil {
+		return err
+	}
+
+	lq, err := api.dao.FindPblLibraryQueryById(c.PathParam("id"))
+	if err != nil || lq == nil {
+		return errResp(c, 404, "Library query not found")
+	}
+
+	var body struct {
+		Name            string      `json:"name"`
+		LibraryQueryDSL interface{} `json:"libraryQueryDSL"`
+	}
+	if err := c.Bind(&body); err != nil {
+		return errResp(c, 400, "Invalid request")
+	}
+
+	before := auditLibraryQuery(lq)
+
+	form := forms.NewLibraryQueryUpsert(api.dao, lq)
+	if body.Name != "" {
+		form.Name = body.Name
+	}
+	if body.LibraryQueryDSL != nil {
+		dslBytes, _ := json.Marshal(body.LibraryQueryDSL)
+		form.EditDsl = string(dslBytes)
+	}
+
+	updated, err := form.Submit()
+	if err != nil {
+		return errResp(c, 400, err.Error())
+	}
+
+	// as with the apps, the dsl autosaves are not audited
+	if after := auditLibraryQuery(updated); !reflect.DeepEqual(before, after) {
+		api.audit(c, "libraryQuery.update", libraryQueryAuditTarget(updated), before, after)
+	}
+	return okResp(c, true)
+}
+
+func (api *openblocksApi) libraryQueryDelete(c echo.Context) error {
+	if err := api.requireAdmin(c); err != nil {
+		return err
+	}
+
+	lq, err := api.dao.FindPblLibraryQueryById(c.PathParam("id"))
+	if err != nil || lq == nil {
+		return errResp(c, 404, "Library query not found")
+	}
+
+	if err := api.dao.DeletePblLibraryQuery(lq); err != nil {
+		return errResp(c, 400, err.Error())
+	}
+	api.audit(c, "libraryQuery.delete", libraryQueryAuditTarget(lq), auditLibraryQuery(lq), nil)
+	return okResp(c, true)
+}
+
+func (api *openblocksApi) libraryQueryPublish(c echo.Context) error {
+	if err := api.requireAdmin(c); err != nil {
+		return err
+	}
+
+	lq, err := api.dao.FindPblLibraryQueryById(c.PathParam("id"))
+	if err != nil || lq == nil {
+		return errResp(c, 404, "Library query not found")
+	}
+
+	before := auditLibraryQuery(lq)
+
+	form := forms.NewLibraryQueryPublish(api.dao, lq)
+	if err := c.Bind(form); err != nil {
+		return errResp(c, 400, "Invalid request")
+	}
+
+	published, err := form.Submit()
+	if err != nil {
+		return errResp(c, 400, err.Error())
+	}
+	api.audit(c, "libraryQuery.publish", libraryQueryAuditTarget(published), before, auditLibraryQuery(published))
+	return okResp(c, api.createLibraryQueryRecordMetas(published)[0])
+}
+
+func (api *openblocksApi) libraryQueryRecordView(c echo.Context) error {
+	if err := api.requireAuth(c); err != nil {
+		return err
+	}
+
+	lq, err := api.dao.FindPblLibraryQueryById(c.QueryParam("libraryQueryId"))
+	if err != nil || lq == nil || !lq.IsPublished() {
+		return errResp(c, 404, "Library query not found")
+	}
+
+	var dsl interface{}
+	json.Unmarshal([]byte(lq.PublishedDsl), &dsl)
+
+	return okResp(c, dsl)
+}
+
+func (api *openblocksApi) libraryQueryRecordList(c echo.Context) error {
+	if err := api.requireAuth(c); err != nil {
+		return err
+	}
+
+	lq, err := api.dao.FindPblLibraryQueryById(c.QueryParam("libraryQueryId"))
+	if err != nil || lq == nil {
+		return errResp(c, 404, "Library query not found")
+	}
+
+	return okResp(c, api.createLibraryQueryRecordMetas(lq))
+}
+
+// --- Configs ---
+
+func (api *openblocksApi) configsView(c echo.Context) error {
+	settings, err := api.dao.GetPblSettings().Clone()
+	if err != nil {
+		return errResp(c, 500, "Failed to load settings")
 	}
 
-	settings, _ := api.dao.GetPblSettings().Clone()
+	return okResp(c, map[string]interface{}{
+		"authConfigs":   api.buildAuthConfigs(),
+		"workspaceMode": "ENTERPRISE",
+		"selfDomain":    false,
+		"cookieName":    "TOKEN",
+		"cloudHosting":  false,
+		"featureFlag": map[string]interface{}{
+			"enableCustomBranding": true,
+		},
+		"branding": map[string]interface{}{
+			"logo":        settings.LogoUrl,
+			"favicon":     settings.IconUrl,
+			"brandName":   settings.Name,
+			"headerColor": settings.HeaderColor,
+		},
+	})
+}
+
+func (api *openblocksApi) configsUpdate(c echo.Context) error {
+	if err := api.requireAdmin(c); err != nil {
+		return err
+	}
+
+	var body struct {
+		Branding *struct {
+			BrandName   string `json:"brandName"`
+			HeaderColor string `json:"headerColor"`
+			Favicon     string `json:"favicon"`
+			Logo        string `json:"logo"`
+		} `json:"branding"`
+		Auths *models.Auths `json:"auths"`
+	}
+	if err := c.Bind(&body); err != nil {
+		return errResp(c, 400, "Invalid request")
+	}
+
+	before, _ := api.dao.GetPblSettings().Clone()
+
+	settingsForm := forms.NewSettingsUpsert(api.dao)
+	if body.Branding != nil {
+		settingsForm.Name = body.Branding.BrandName
+		settingsForm.HeaderColor = body.Branding.HeaderColor
+		settingsForm.IconUrl = body.Branding.Favicon
+		settingsForm.LogoUrl = body.Branding.Logo
+	}
+	if body.Auths != nil {
+		settingsForm.Auths = *body.Auths
+	}
+
+	if err := settingsForm.Submit(); err != nil {
+		return errResp(c, 400, err.Error())
+	}
+
+	settings, _ := api.dao.GetPblSettings().Clone()
+	api.auditSettings(c, before, settings)
 	return okResp(c, map[string]interface{}{
 		"authConfigs":   api.buildAuthConfigs(),
 		"workspaceMode": "ENTERPRISE",
@@ -1910,6 +3335,8 @@ func (api *openblocksApi) orgCommonSettingsUpdate(c echo.Context) error {
 		valueStr = string(bytes)
 	}
 
+	before, _ := api.dao.GetPblSettings().Clone()
+
 	settingsForm := forms.NewSettingsUpsert(api.dao)
 	switch settingsKey {
 	case "themes":
@@ -1931,6 +3358,9 @@ func (api *openblocksApi) orgCommonSettingsUpdate(c echo.Context) error {
 	if err := settingsForm.Submit(); err != nil {
 		return errResp(c, 400, err.Error())
 	}
+
+	after, _ := api.dao.GetPblSettings().Clone()
+	api.auditSettings(c, before, after)
 	return okResp(c, true)
 }
 
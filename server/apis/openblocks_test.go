@@ -0,0 +1,51 @@
+package apis
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+)
+
+func TestRequireGuardsStopDeniedRequests(t *testing.T) {
+	api := &openblocksApi{}
+
+	scenarios := []struct {
+		name  string
+		guard func(c echo.Context) error
+	}{
+		{"requireAuth", api.requireAuth},
+		{"requireAdmin", api.requireAdmin},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			reached := false
+
+			e := echo.New()
+			e.GET("/guarded", func(c echo.Context) error {
+				if err := s.guard(c); err != nil {
+					return err
+				}
+				reached = true
+				return okResp(c, true)
+			})
+
+			// no auth token
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/guarded", nil))
+
+			if reached {
+				t.Fatal("Expected the guarded handler to stop after the denied request")
+			}
+			if rec.Code != http.StatusUnauthorized {
+				t.Fatalf("Expected status %d, got %d", http.StatusUnauthorized, rec.Code)
+			}
+			if body := rec.Body.String(); strings.Count(body, `"success"`) != 1 || !strings.Contains(body, "Unauthorized") {
+				t.Fatalf("Expected a single unauthorized response, got %s", body)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package apis
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/pedrozadotdev/pocketblocks/server/daos"
 	"github.com/pedrozadotdev/pocketblocks/server/forms"
@@ -18,6 +19,7 @@ func BindSnapshotApi(dao *daos.Dao, g *echo.Group, logMiddleware echo.Middleware
 	subGroup.GET("", api.list, apis.RequireAdminAuth())
 	subGroup.GET("/:id", api.view, apis.RequireAdminAuth())
 	subGroup.POST("", api.create, apis.RequireAdminAuth(), logMiddleware)
+	subGroup.POST("/prune", api.prune, apis.RequireAdminAuth(), logMiddleware)
 
 }
 
@@ -41,8 +43,9 @@ func (api *snapshotApi) view(c echo.Context) error {
 }
 
 func (api *snapshotApi) list(c echo.Context) error {
+	// the dsl is stored compressed, so it can't be filtered or sorted
 	fieldResolver := search.NewSimpleFieldResolver(
-		"id", "app", "dsl", "context", "created", "updated",
+		"id", "app", "context", "created", "updated",
 	)
 
 	snapshots := []*models.Snapshot{}
@@ -74,3 +77,23 @@ func (api *snapshotApi) create(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, snapshot)
 }
+
+// prune removes the snapshots not covered by the retention policy
+// right away and returns what was removed.
+func (api *snapshotApi) prune(c echo.Context) error {
+	settings, err := api.dao.GetPblSettings().Clone()
+	if err != nil {
+		return apis.NewApiError(500, "Something went wrong", err)
+	}
+
+	if !settings.SnapshotRetention.Enabled {
+		return apis.NewBadRequestError("The snapshot retention policy is disabled.", nil)
+	}
+
+	results, err := api.dao.PrunePblSnapshots(settings.SnapshotRetention, time.Now())
+	if err != nil {
+		return apis.NewBadRequestError("Failed to prune the snapshots.", err)
+	}
+
+	return c.JSON(http.StatusOK, results)
+}
@@ -0,0 +1,452 @@
+// Package appbundle implements the export and import of a single
+// PocketBlocks application as a portable zip bundle, so apps can be moved
+// between instances (eg. from staging to production).
+//
+// A bundle holds an "app.json" file (see [Bundle]) and the referenced
+// public dir assets under "assets/".
+package appbundle
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pedrozadotdev/pocketblocks/server/daos"
+	"github.com/pedrozadotdev/pocketblocks/server/forms"
+	"github.com/pedrozadotdev/pocketblocks/server/models"
+	"github.com/pocketbase/dbx"
+	pbDaos "github.com/pocketbase/pocketbase/daos"
+)
+
+// Version is the current bundle format version.
+const Version = 1
+
+const (
+	manifestName = "app.json"
+	assetsDir    = "assets/"
+)
+
+// Bundle is the manifest of an exported application.
+//
+// Groups and users are referenced by name and email (instead of their ids)
+// and the folder by its names path, so they can be matched on another instance.
+type Bundle struct {
+	Version    int        `json:"version"`
+	ExportedAt string     `json:"exportedAt"`
+	App        App        `json:"app"`
+	Folder     []string   `json:"folder"`
+	Groups     []Member   `json:"groups"`
+	Users      []Member   `json:"users"`
+	Snapshots  []Snapshot `json:"snapshots,omitempty"`
+	Assets     []string   `json:"assets"`
+}
+
+// App holds the exported [models.Application] fields.
+type App struct {
+	Name         string `json:"name"`
+	Slug         string `json:"slug"`
+	Type         int    `json:"type"`
+	Status       string `json:"status"`
+	Public       bool   `json:"public"`
+	AllUsers     bool   `json:"allUsers"`
+	AllUsersRole string `json:"allUsersRole,omitempty"`
+	AppDsl       string `json:"appDSL"`
+	EditDsl      string `json:"editDSL"`
+	IsTemplate   bool   `json:"isTemplate,omitempty"`
+}
+
+// Member is a group (by name) or user (by email) with access to the app.
+type Member struct {
+	Key  string `json:"key"`
+	Role string `json:"role"`
+}
+
+// Snapshot is an exported app history snapshot.
+type Snapshot struct {
+	Dsl     string `json:"dsl"`
+	Context string `json:"context"`
+	Created string `json:"created"`
+}
+
+// ExportOptions defines the optional export parts.
+type ExportOptions struct {
+	Snapshots bool
+}
+
+// ImportResult reports how the bundle was imported.
+type ImportResult struct {
+	App              *models.Application `json:"app"`
+	OriginalSlug     string              `json:"originalSlug"`
+	Snapshots        int                 `json:"snapshots"`
+	UnresolvedGroups []string            `json:"unresolvedGroups"`
+	UnresolvedUsers  []string            `json:"unresolvedUsers"`
+	ImportedAssets   []string            `json:"importedAssets"`
+	SkippedAssets    []string            `json:"skippedAssets"`
+}
+
+var assetRegex = regexp.MustCompile(`/pbl/[^"'\s\\)?#]+`)
+
+// FindAssets returns the unique public dir paths (relative to the public dir)
+// referenced as "/pbl/..." urls in the provided strings.
+func FindAssets(contents ...string) []string {
+	result := []string{}
+	for _, content := range contents {
+		for _, match := range assetRegex.FindAllString(content, -1) {
+			p := path.Clean(strings.TrimPrefix(match, "/pbl/"))
+			if !filepath.IsLocal(p) || slices.Contains(result, p) {
+				continue
+			}
+			result = append(result, p)
+		}
+	}
+	slices.Sort(result)
+	return result
+}
+
+// Export writes the zip bundle of the provided app into w.
+func Export(dao *daos.Dao, publicDir string, app *models.Application, options ExportOptions, w io.Writer) error {
+	bundle := &Bundle{
+		Version:    Version,
+		ExportedAt: time.Now().UTC().Format(time.RFC3339),
+		App: App{
+			Name:       app.Name,
+			Slug:       app.Slug,
+			Type:       app.Type,
+			Status:     app.Status,
+			Public:     app.Public,
+			AllUsers:   app.AllUsers,
+			AppDsl:     app.AppDsl,
+			EditDsl:    app.EditDsl,
+			IsTemplate: app.IsTemplate,
+		},
+		Folder: []string{},
+		Groups: []Member{},
+		Users:  []Member{},
+	}
+	if app.AllUsers {
+		bundle.App.AllUsersRole = app.MemberRole("all_users|GROUP")
+	}
+
+	// folder names path (root first)
+	visited := map[string]bool{}
+	for folderId := app.FolderId.String; folderId != "" && !visited[folderId]; {
+		visited[folderId] = true
+		folder, err := dao.FindPblFolderById(folderId)
+		if err != nil {
+			return err
+		}
+		bundle.Folder = append([]string{folder.Name}, bundle.Folder...)
+		folderId = folder.ParentId.String
+	}
+
+	for _, gId := range app.Groups {
+		group, err := dao.FindRecordById("groups", gId)
+		if err != nil {
+			continue
+		}
+		bundle.Groups = append(bundle.Groups, Member{
+			Key:  group.GetString("name"),
+			Role: app.MemberRole(gId + "|GROUP"),
+		})
+	}
+
+	for _, uId := range app.Users {
+		user, err := dao.FindRecordById("users", uId)
+		if err != nil || user.Email() == "" {
+			continue
+		}
+		bundle.Users = append(bundle.Users, Member{
+			Key:  user.Email(),
+			Role: app.MemberRole(uId + "|USER"),
+		})
+	}
+
+	dslContents := []string{app.AppDsl, app.EditDsl}
+
+	if options.Snapshots {
+		snapshots := []*models.Snapshot{}
+		if err := dao.PblSnapshotQuery().
+			AndWhere(dbx.HashExp{"app": app.Id}).
+			OrderBy("created ASC").
+			All(&snapshots); err != nil {
+			return err
+		}
+		for _, s := range snapshots {
+			bundle.Snapshots = append(bundle.Snapshots, Snapshot{
+				Dsl:     s.Dsl,
+				Context: s.Context,
+				Created: s.Created.String(),
+			})
+			dslContents = append(dslContents, s.Dsl)
+		}
+	}
+
+	// only the existing assets are bundled
+	bundle.Assets = []string{}
+	for _, asset := range FindAssets(dslContents...) {
+		if info, err := os.Stat(filepath.Join(publicDir, asset)); err == nil && !info.IsDir() {
+			bundle.Assets = append(bundle.Assets, asset)
+		}
+	}
+
+	zw := zip.NewWriter(w)
+
+	manifest, err := zw.Create(manifestName)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(manifest)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(bundle); err != nil {
+		return err
+	}
+
+	for _, asset := range bundle.Assets {
+		if err := addZipFile(zw, assetsDir+asset, filepath.Join(publicDir, asset)); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+func addZipFile(zw *zip.Writer, name string, src string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	dst, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(dst, f)
+	return err
+}
+
+// Read parses the bundle manifest from the provided zip archive.
+func Read(zr *zip.Reader) (*Bundle, error) {
+	f, err := zr.Open(manifestName)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bundle: %w", err)
+	}
+	defer f.Close()
+
+	bundle := &Bundle{}
+	if err := json.NewDecoder(f).Decode(bundle); err != nil {
+		return nil, fmt.Errorf("invalid bundle manifest: %w", err)
+	}
+
+	if bundle.Version > Version {
+		return nil, fmt.Errorf("unsupported bundle version %d", bundle.Version)
+	}
+
+	return bundle, nil
+}
+
+// Import creates a new application from the provided zip bundle.
+//
+// The app slug is derived from the app name, like on create, so it may
+// differ from the bundled one (see [ImportResult.OriginalSlug]). The module
+// references (slugs) inside the imported DSLs are kept as they are.
+//
+// Groups and users are matched by name and email (the unresolved ones are
+// skipped) and the bundled assets are copied into publicDir unless they
+// already exist.
+func Import(dao *daos.Dao, publicDir string, r io.ReaderAt, size int64) (*ImportResult, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle, err := Read(zr)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{
+		OriginalSlug:     bundle.App.Slug,
+		UnresolvedGroups: []string{},
+		UnresolvedUsers:  []string{},
+		ImportedAssets:   []string{},
+		SkippedAssets:    []string{},
+	}
+
+	txErr := dao.RunInTransaction(func(txDao *pbDaos.Dao) error {
+		dao := daos.New(txDao.DB())
+
+		folderId, err := ensureFolderPath(dao, bundle.Folder)
+		if err != nil {
+			return err
+		}
+
+		roles := map[string]string{}
+		groupIds := []string{}
+		for _, g := range bundle.Groups {
+			group, err := dao.FindFirstRecordByData("groups", "name", g.Key)
+			if err != nil {
+				result.UnresolvedGroups = append(result.UnresolvedGroups, g.Key)
+				continue
+			}
+			groupIds = append(groupIds, group.Id)
+			roles[group.Id+"|GROUP"] = g.Role
+		}
+
+		userIds := []string{}
+		for _, u := range bundle.Users {
+			user, err := dao.FindAuthRecordByEmail("users", u.Key)
+			if err != nil {
+				result.UnresolvedUsers = append(result.UnresolvedUsers, u.Key)
+				continue
+			}
+			userIds = append(userIds, user.Id)
+			roles[user.Id+"|USER"] = u.Role
+		}
+
+		if bundle.App.AllUsers && bundle.App.AllUsersRole != "" {
+			roles["all_users|GROUP"] = bundle.App.AllUsersRole
+		}
+
+		form := forms.NewApplicationUpsert(dao, &models.Application{})
+		form.Name = bundle.App.Name
+		form.Type = bundle.App.Type
+		form.Status = bundle.App.Status
+		form.Public = bundle.App.Public
+		form.AllUsers = bundle.App.AllUsers
+		form.IsTemplate = bundle.App.IsTemplate
+		form.Groups = groupIds
+		form.Users = userIds
+		form.Roles = roles
+		form.AppDsl = bundle.App.AppDsl
+		form.EditDsl = bundle.App.EditDsl
+		form.FolderId = folderId
+		if form.AppDsl == "" {
+			form.AppDsl = "{}"
+		}
+		if form.Status == "" {
+			form.Status = "NORMAL"
+		}
+
+		app, err := form.Submit()
+		if err != nil {
+			return err
+		}
+		result.App = app
+
+		for _, s := range bundle.Snapshots {
+			snapshot := &models.Snapshot{}
+			if s.Created != "" {
+				snapshot.Created.Scan(s.Created)
+			}
+
+			snapshotForm := forms.NewSnapshotUpsert(dao, snapshot)
+			snapshotForm.AppId = app.Id
+			snapshotForm.Dsl = s.Dsl
+			snapshotForm.Context = s.Context
+			if _, err := snapshotForm.Submit(); err != nil {
+				return err
+			}
+			result.Snapshots++
+		}
+
+		return nil
+	})
+	if txErr != nil {
+		return nil, txErr
+	}
+
+	for _, asset := range bundle.Assets {
+		imported, err := importAsset(zr, publicDir, asset)
+		if err != nil {
+			return result, err
+		}
+		if imported {
+			result.ImportedAssets = append(result.ImportedAssets, asset)
+		} else {
+			result.SkippedAssets = append(result.SkippedAssets, asset)
+		}
+	}
+
+	return result, nil
+}
+
+// ensureFolderPath returns the id of the folder matching the provided names
+// path, creating the missing folders along the way.
+func ensureFolderPath(dao *daos.Dao, names []string) (string, error) {
+	parentId := ""
+
+	for _, name := range names {
+		var parentExp dbx.Expression = dbx.HashExp{"parent": parentId}
+		if parentId == "" {
+			parentExp = dbx.Or(dbx.HashExp{"parent": ""}, dbx.HashExp{"parent": nil})
+		}
+
+		folder := &models.Folder{}
+		err := dao.PblFolderQuery().
+			AndWhere(dbx.HashExp{"name": name}).
+			AndWhere(parentExp).
+			Limit(1).
+			One(folder)
+		if err != nil {
+			form := forms.NewFolderUpsert(dao, &models.Folder{})
+			form.Name = name
+			form.ParentId = parentId
+			if folder, err = form.Submit(); err != nil {
+				return "", err
+			}
+		}
+
+		parentId = folder.Id
+	}
+
+	return parentId, nil
+}
+
+// importAsset copies the bundled asset into publicDir
+// (existing files are never overwritten).
+func importAsset(zr *zip.Reader, publicDir string, asset string) (bool, error) {
+	if !filepath.IsLocal(asset) {
+		return false, nil
+	}
+
+	dst := filepath.Join(publicDir, asset)
+	if _, err := os.Stat(dst); err == nil {
+		return false, nil
+	}
+
+	src, err := zr.Open(assetsDir + asset)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer src.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+		return false, err
+	}
+
+	f, err := os.Create(dst)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, src); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,31 @@
+package appbundle
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFindAssets(t *testing.T) {
+	scenarios := []struct {
+		contents []string
+		expected []string
+	}{
+		{[]string{""}, []string{}},
+		{[]string{`{"src":"https://example.com/logo.png"}`}, []string{}},
+		{
+			[]string{
+				`{"src":"/pbl/images/logo.png","bg":"url('/pbl/bg.jpg')"}`,
+				`{"icon":"/pbl/images/logo.png?v=2","other":"/pbl/docs/a b.pdf"}`,
+			},
+			[]string{"bg.jpg", "docs/a", "images/logo.png"},
+		},
+		{[]string{`{"src":"/pbl/../secret.txt","ok":"/pbl/a/../b.png"}`}, []string{"b.png"}},
+	}
+
+	for i, s := range scenarios {
+		result := FindAssets(s.contents...)
+		if !slices.Equal(result, s.expected) {
+			t.Errorf("[%d] Expected %v, got %v", i, s.expected, result)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package appbundle
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/pedrozadotdev/pocketblocks/server/daos"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/spf13/cobra"
+)
+
+// Config defines the config options of the app bundle commands.
+type Config struct {
+	// PublicDir is the directory served at <host>/pbl/*
+	// (where the bundled assets are read from and copied to).
+	PublicDir string
+}
+
+// MustRegister registers the "app export|import" commands to the provided
+// app instance and panic if it fails.
+//
+// Example usage:
+//
+//	appbundle.MustRegister(app, app.RootCmd, appbundle.Config{PublicDir: publicDir})
+func MustRegister(app core.App, rootCmd *cobra.Command, config Config) {
+	if err := Register(app, rootCmd, config); err != nil {
+		panic(err)
+	}
+}
+
+// Register registers the "app export|import" commands to the provided app instance.
+func Register(app core.App, rootCmd *cobra.Command, config Config) error {
+	command := &cobra.Command{
+		Use:   "app",
+		Short: "Exports and imports PocketBlocks applications as portable bundles",
+	}
+
+	command.AddCommand(exportCmd(app, config), importCmd(app, config))
+	rootCmd.AddCommand(command)
+
+	return nil
+}
+
+func exportCmd(app core.App, config Config) *cobra.Command {
+	var output string
+	var withSnapshots bool
+
+	command := &cobra.Command{
+		Use:          "export [slug]",
+		Short:        "Exports the application with the provided slug into a zip bundle",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(command *cobra.Command, args []string) error {
+			dao := daos.New(app.Dao().DB())
+
+			application, err := dao.FindPblAppBySlug(args[0], nil)
+			if err != nil {
+				return fmt.Errorf("application %q not found", args[0])
+			}
+
+			if output == "" {
+				output = application.Slug + ".zip"
+			}
+
+			f, err := os.Create(output)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			if err := Export(dao, config.PublicDir, application, ExportOptions{Snapshots: withSnapshots}, f); err != nil {
+				os.Remove(output)
+				return err
+			}
+
+			color.Green("Successfully exported %q to %s", application.Slug, output)
+			return nil
+		},
+	}
+
+	command.Flags().StringVarP(&output, "output", "o", "", "the bundle file path (default to <slug>.zip)")
+	command.Flags().BoolVar(&withSnapshots, "snapshots", false, "include the application history snapshots")
+
+	return command
+}
+
+func importCmd(app core.App, config Config) *cobra.Command {
+	return &cobra.Command{
+		Use:          "import [file]",
+		Short:        "Imports an application from a zip bundle",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(command *cobra.Command, args []string) error {
+			f, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			info, err := f.Stat()
+			if err != nil {
+				return err
+			}
+
+			result, err := Import(daos.New(app.Dao().DB()), config.PublicDir, f, info.Size())
+			if err != nil {
+				return err
+			}
+
+			color.Green("Successfully imported %q as %q", result.OriginalSlug, result.App.Slug)
+			if result.Snapshots > 0 {
+				fmt.Printf("Snapshots: %d\n", result.Snapshots)
+			}
+			if len(result.ImportedAssets) > 0 {
+				fmt.Printf("Imported assets: %v\n", result.ImportedAssets)
+			}
+			if len(result.SkippedAssets) > 0 {
+				color.Yellow("Skipped existing assets: %v", result.SkippedAssets)
+			}
+			if len(result.UnresolvedGroups) > 0 {
+				color.Yellow("Unresolved groups (skipped): %v", result.UnresolvedGroups)
+			}
+			if len(result.UnresolvedUsers) > 0 {
+				color.Yellow("Unresolved users (skipped): %v", result.UnresolvedUsers)
+			}
+
+			return nil
+		},
+	}
+}
@@ -5,7 +5,10 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/pedrozadotdev/pocketblocks/server/appbundle"
 	"github.com/pedrozadotdev/pocketblocks/server/ghupdate"
+	"github.com/pedrozadotdev/pocketblocks/server/gitsync"
+	"github.com/pedrozadotdev/pocketblocks/server/workspace"
 	"github.com/pocketbase/pocketbase"
 	"github.com/pocketbase/pocketbase/plugins/jsvm"
 	"github.com/pocketbase/pocketbase/plugins/migratecmd"
@@ -64,6 +67,22 @@ func RegisterCommands(app *pocketbase.PocketBase) {
 		"the directory to serve static files at <host>/pbl/* path",
 	)
 
+	var gitSyncDir string
+	app.RootCmd.PersistentFlags().StringVar(
+		&gitSyncDir,
+		"gitSyncDir",
+		"",
+		"the git repository directory where the apps are mirrored (disabled if empty)",
+	)
+
+	var gitSyncRemote string
+	app.RootCmd.PersistentFlags().StringVar(
+		&gitSyncRemote,
+		"gitSyncRemote",
+		"",
+		"the optional git remote (url or local bare repo path) of the apps mirror",
+	)
+
 	var queryTimeout int
 	app.RootCmd.PersistentFlags().IntVar(
 		&queryTimeout,
@@ -96,7 +115,20 @@ func RegisterCommands(app *pocketbase.PocketBase) {
 	// GitHub selfupdate
 	ghupdate.MustRegister(app, app.RootCmd, ghupdate.Config{})
 
+	// app export/import
+	appbundle.MustRegister(app, app.RootCmd, appbundle.Config{PublicDir: publicDir})
+
+	// workspace backup/restore
+	workspace.MustRegister(app, app.RootCmd, workspace.Config{PublicDir: publicDir})
+
+	// apps git sync
+	gitsync.MustRegister(app, app.RootCmd, gitsync.Config{
+		Dir:    gitSyncDir,
+		Remote: gitSyncRemote,
+	})
+
 	registerHooks(app, publicDir, queryTimeout)
+	registerCronJobs(app)
 }
 
 // the default pb_public dir location is relative to the executable
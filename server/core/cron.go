@@ -0,0 +1,104 @@
+package core
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/pedrozadotdev/pocketblocks/server/daos"
+	"github.com/pedrozadotdev/pocketblocks/server/forms"
+	"github.com/pedrozadotdev/pocketblocks/server/models"
+	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/cron"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// registerCronJobs starts the PocketBlocks background jobs when the app is served.
+//
+// The jobs are checked every minute against the schedules stored in the
+// PocketBlocks settings, so settings changes are picked up without a restart.
+func registerCronJobs(app *pocketbase.PocketBase) {
+	c := cron.New()
+
+	c.MustAdd("@pblSnapshotPrune", "* * * * *", func() {
+		dao := daos.New(app.Dao().DB())
+
+		settings, err := dao.GetPblSettings().Clone()
+		if err != nil {
+			return
+		}
+
+		retention := settings.SnapshotRetention
+		if !retention.Enabled || retention.Cron == "" {
+			return
+		}
+
+		schedule, err := cron.NewSchedule(retention.Cron)
+		if err != nil || !schedule.IsDue(cron.NewMoment(time.Now())) {
+			return
+		}
+
+		pruneSnapshots(app, dao, retention)
+	})
+
+	c.MustAdd("@pblReleases", "* * * * *", func() {
+		applyDueReleases(app, daos.New(app.Dao().DB()))
+	})
+
+	app.OnBeforeServe().Add(func(e *core.ServeEvent) error {
+		c.Start()
+		return nil
+	})
+
+	app.OnTerminate().Add(func(e *core.TerminateEvent) error {
+		c.Stop()
+		return nil
+	})
+}
+
+// pruneSnapshots removes the app snapshots not covered by the retention
+// policy anymore and reports the removed ones in the app logs.
+func pruneSnapshots(app *pocketbase.PocketBase, dao *daos.Dao, retention models.SnapshotRetention) {
+	results, err := dao.PrunePblSnapshots(retention, time.Now())
+
+	total := 0
+	for _, r := range results {
+		total += len(r.Removed)
+		app.Logger().Info(
+			"[Snapshot prune] Removed app snapshots",
+			slog.String("app", r.AppId),
+			slog.Int("total", len(r.Removed)),
+			slog.Any("snapshots", r.Removed),
+		)
+	}
+
+	if err != nil {
+		app.Logger().Error("[Snapshot prune] Failed to prune the app snapshots", slog.String("error", err.Error()))
+		return
+	}
+
+	app.Logger().Info("[Snapshot prune] Done", slog.Int("apps", len(results)), slog.Int("total", total))
+}
+
+// applyDueReleases publishes the approved releases whose scheduled time has come.
+func applyDueReleases(app *pocketbase.PocketBase, dao *daos.Dao) {
+	releases, err := dao.FindPblDueReleases(types.NowDateTime())
+	if err != nil {
+		app.Logger().Error("[Releases] Failed to load the due releases", slog.String("error", err.Error()))
+		return
+	}
+
+	for _, release := range releases {
+		if _, err := forms.NewReleaseApply(dao, release).Submit(); err != nil {
+			app.Logger().Error(
+				"[Releases] Failed to apply the release",
+				slog.String("release", release.Id),
+				slog.String("app", release.AppId),
+				slog.String("error", err.Error()),
+			)
+			continue
+		}
+
+		app.Logger().Info("[Releases] Applied scheduled release", slog.String("release", release.Id), slog.String("app", release.AppId))
+	}
+}
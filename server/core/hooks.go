@@ -69,7 +69,7 @@ func registerHooks(app *pocketbase.PocketBase, publicDir string, queryTimeout in
 			uiCacheControl(),
 			middleware.Gzip(),
 		)
-		registerRoutes(app, e.Router)
+		registerRoutes(app, e.Router, publicDir)
 
 		dao := daos.New(app.Dao().DB())
 
@@ -8,7 +8,7 @@ import (
 	a "github.com/pocketbase/pocketbase/apis"
 )
 
-func registerRoutes(app *pocketbase.PocketBase, e *echo.Echo) {
+func registerRoutes(app *pocketbase.PocketBase, e *echo.Echo, publicDir string) {
 	group := e.Group("/api/pbl")
 	dao := daos.New(app.Dao().DB())
 	logMiddleware := a.ActivityLogger(app.App)
@@ -17,6 +17,11 @@ func registerRoutes(app *pocketbase.PocketBase, e *echo.Echo) {
 	apis.BindFolderApi(dao, group, logMiddleware)
 	apis.BindSettingsApi(dao, group, logMiddleware)
 	apis.BindApplicationApi(dao, group, logMiddleware)
+	apis.BindDatasourceApi(dao, group, logMiddleware)
+	apis.BindLibraryQueryApi(dao, group, logMiddleware)
+	apis.BindBundleApi(dao, publicDir, group, logMiddleware)
+	apis.BindAuditLogApi(dao, group, logMiddleware)
+	apis.BindAnalyticsApi(dao, group, logMiddleware)
 
 	ob := apis.BindOpenblocksApi(app, dao, e)
 	apis.BindAiApi(app, dao, ob, e)
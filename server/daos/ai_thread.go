@@ -0,0 +1,59 @@
+package daos
+
+import (
+	"encoding/json"
+
+	m "github.com/pedrozadotdev/pocketblocks/server/models"
+	"github.com/pocketbase/dbx"
+)
+
+func (dao *Dao) PblAiThreadQuery() *dbx.SelectQuery {
+	return dao.ModelQuery(&m.AiThread{})
+}
+
+func (dao *Dao) FindPblAiThreadById(id string) (*m.AiThread, error) {
+	model := &m.AiThread{}
+
+	err := dao.PblAiThreadQuery().
+		AndWhere(dbx.HashExp{"id": id}).
+		Limit(1).
+		One(model)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
+
+// FindPblAiThreads returns the AI threads of the user about the app
+// (the most recently updated first).
+func (dao *Dao) FindPblAiThreads(appId string, userId string) ([]*m.AiThread, error) {
+	threads := []*m.AiThread{}
+
+	err := dao.PblAiThreadQuery().
+		AndWhere(dbx.HashExp{"app": appId, "user": userId}).
+		OrderBy("updated DESC").
+		All(&threads)
+
+	return threads, err
+}
+
+func (dao *Dao) DeletePblAiThread(thread *m.AiThread) error {
+	return dao.Delete(thread)
+}
+
+// SavePblAiThread persists the thread, encoding its messages.
+func (dao *Dao) SavePblAiThread(thread *m.AiThread) error {
+	if thread.Messages == nil {
+		thread.Messages = []*m.AiThreadMessage{}
+	}
+
+	rawMessages, err := json.Marshal(thread.Messages)
+	if err != nil {
+		return err
+	}
+	thread.RawMessages = string(rawMessages)
+
+	return dao.Save(thread)
+}
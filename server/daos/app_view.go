@@ -0,0 +1,135 @@
+package daos
+
+import (
+	"time"
+
+	m "github.com/pedrozadotdev/pocketblocks/server/models"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func (dao *Dao) PblAppViewQuery() *dbx.SelectQuery {
+	return dao.ModelQuery(&m.AppView{})
+}
+
+func (dao *Dao) SavePblAppView(view *m.AppView) error {
+	return dao.Save(view)
+}
+
+// FindPblAppLastViews returns the last time the viewer opened each app
+// (indexed by app id, the never viewed apps are omitted).
+func (dao *Dao) FindPblAppLastViews(viewer string) (map[string]types.DateTime, error) {
+	rows := []struct {
+		AppId    string         `db:"app"`
+		LastView types.DateTime `db:"lastView"`
+	}{}
+
+	err := dao.PblAppViewQuery().
+		Select("app", "MAX([[created]]) AS [[lastView]]").
+		AndWhere(dbx.HashExp{"viewer": viewer}).
+		GroupBy("app").
+		All(&rows)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]types.DateTime, len(rows))
+	for _, r := range rows {
+		result[r.AppId] = r.LastView
+	}
+
+	return result, nil
+}
+
+// PblAppDailyViewers sums up the views of an app in a single day (UTC).
+//
+// The anonymous views (public apps) are counted in Views but not in Viewers.
+type PblAppDailyViewers struct {
+	AppId   string `db:"app" json:"app"`
+	Day     string `db:"day" json:"day"`
+	Viewers int    `db:"viewers" json:"viewers"`
+	Views   int    `db:"views" json:"views"`
+}
+
+// PblAppUsage sums up the views of an app in a period.
+//
+// The anonymous views (public apps) are counted in Views but not in Viewers.
+type PblAppUsage struct {
+	AppId    string         `db:"app" json:"app"`
+	Viewers  int            `db:"viewers" json:"viewers"`
+	Views    int            `db:"views" json:"views"`
+	LastView types.DateTime `db:"lastView" json:"lastView"`
+}
+
+// FindPblAppDailyViewers returns the daily views of every app viewed
+// in the [from, to) period, ordered by day.
+func (dao *Dao) FindPblAppDailyViewers(from, to time.Time) ([]*PblAppDailyViewers, error) {
+	result := []*PblAppDailyViewers{}
+
+	err := dao.pblAppViewsBetween(from, to).
+		Select(
+			"app",
+			"substr([[created]], 1, 10) AS [[day]]",
+			"COUNT(DISTINCT NULLIF([[viewer]], '')) AS [[viewers]]",
+			"COUNT(*) AS [[views]]",
+		).
+		GroupBy("day", "app").
+		OrderBy("day ASC", "viewers DESC", "app ASC").
+		All(&result)
+
+	return result, err
+}
+
+// FindPblAppsUsage returns the usage of the apps viewed in the [from, to)
+// period, the most viewed first.
+//
+// Set limit to 0 to return all of them.
+func (dao *Dao) FindPblAppsUsage(from, to time.Time, limit int64) ([]*PblAppUsage, error) {
+	result := []*PblAppUsage{}
+
+	query := dao.pblAppViewsBetween(from, to).
+		Select(
+			"app",
+			"COUNT(DISTINCT NULLIF([[viewer]], '')) AS [[viewers]]",
+			"COUNT(*) AS [[views]]",
+			"MAX([[created]]) AS [[lastView]]",
+		).
+		GroupBy("app").
+		OrderBy("views DESC", "viewers DESC", "app ASC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	err := query.All(&result)
+
+	return result, err
+}
+
+// FindPblUnusedApps returns the (not recycled) apps without any view
+// in the [from, to) period.
+func (dao *Dao) FindPblUnusedApps(from, to time.Time) ([]*m.Application, error) {
+	apps := []*m.Application{}
+
+	err := dao.PblAppQuery().
+		AndWhere(dbx.NewExp("[[status]] != 'RECYCLED'")).
+		AndWhere(dbx.NewExp(
+			"NOT EXISTS (SELECT 1 FROM {{_pbl_app_views}} WHERE [[_pbl_app_views.app]] = [[_pbl_apps.id]] AND [[_pbl_app_views.created]] >= {:from} AND [[_pbl_app_views.created]] < {:to})",
+			pblAppViewsRangeParams(from, to),
+		)).
+		OrderBy("name ASC").
+		All(&apps)
+
+	return apps, err
+}
+
+func (dao *Dao) pblAppViewsBetween(from, to time.Time) *dbx.SelectQuery {
+	return dao.PblAppViewQuery().
+		AndWhere(dbx.NewExp("[[created]] >= {:from} AND [[created]] < {:to}", pblAppViewsRangeParams(from, to)))
+}
+
+func pblAppViewsRangeParams(from, to time.Time) dbx.Params {
+	return dbx.Params{
+		"from": from.UTC().Format(types.DefaultDateLayout),
+		"to":   to.UTC().Format(types.DefaultDateLayout),
+	}
+}
@@ -9,6 +9,21 @@ func (dao *Dao) PblAppQuery() *dbx.SelectQuery {
 	return dao.ModelQuery(&m.Application{})
 }
 
+func (dao *Dao) FindPblAppById(id string) (*m.Application, error) {
+	model := &m.Application{}
+
+	err := dao.PblAppQuery().
+		AndWhere(dbx.HashExp{"id": id}).
+		Limit(1).
+		One(model)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
+
 func (dao *Dao) FindPblAppBySlug(slug string, filterExpr dbx.Expression) (*m.Application, error) {
 	model := &m.Application{}
 
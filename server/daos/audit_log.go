@@ -0,0 +1,29 @@
+package daos
+
+import (
+	m "github.com/pedrozadotdev/pocketblocks/server/models"
+	"github.com/pocketbase/dbx"
+)
+
+func (dao *Dao) PblAuditLogQuery() *dbx.SelectQuery {
+	return dao.ModelQuery(&m.AuditLog{})
+}
+
+func (dao *Dao) FindPblAuditLogById(id string) (*m.AuditLog, error) {
+	model := &m.AuditLog{}
+
+	err := dao.PblAuditLogQuery().
+		AndWhere(dbx.HashExp{"id": id}).
+		Limit(1).
+		One(model)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
+
+func (dao *Dao) SavePblAuditLog(log *m.AuditLog) error {
+	return dao.Save(log)
+}
@@ -0,0 +1,33 @@
+package daos
+
+import (
+	m "github.com/pedrozadotdev/pocketblocks/server/models"
+	"github.com/pocketbase/dbx"
+)
+
+func (dao *Dao) PblDatasourceQuery() *dbx.SelectQuery {
+	return dao.ModelQuery(&m.Datasource{})
+}
+
+func (dao *Dao) FindPblDatasourceById(id string) (*m.Datasource, error) {
+	model := &m.Datasource{}
+
+	err := dao.PblDatasourceQuery().
+		AndWhere(dbx.HashExp{"id": id}).
+		Limit(1).
+		One(model)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
+
+func (dao *Dao) DeletePblDatasource(datasource *m.Datasource) error {
+	return dao.Delete(datasource)
+}
+
+func (dao *Dao) SavePblDatasource(datasource *m.Datasource) error {
+	return dao.Save(datasource)
+}
@@ -38,6 +38,62 @@ func (dao *Dao) PblFolderIsEmpty(id string) (bool, error) {
 	if err := dao.PblAppQuery().Select("count(*)").Where(dbx.HashExp{"folder": id}).Row(&total); err != nil {
 		return false, err
 	}
+	if total > 0 {
+		return false, nil
+	}
+
+	if err := dao.PblFolderQuery().Select("count(*)").Where(dbx.HashExp{"parent": id}).Row(&total); err != nil {
+		return false, err
+	}
 
 	return total == 0, nil
 }
+
+// PblFolderHasMember checks whether the user (or one of its groups) was granted
+// access to the folder or to one of its ancestors.
+func (dao *Dao) PblFolderHasMember(folderId string, userId string, groupIds []string) bool {
+	visited := map[string]bool{}
+
+	for folderId != "" && !visited[folderId] {
+		visited[folderId] = true
+
+		folder, err := dao.FindPblFolderById(folderId)
+		if err != nil {
+			return false
+		}
+		if folder.HasMember(userId, groupIds) {
+			return true
+		}
+		folderId = folder.ParentId.String
+	}
+
+	return false
+}
+
+// FindPblFolderIdsByMember returns the ids of all folders the user can access,
+// either from a grant on the folder itself or inherited from an ancestor.
+func (dao *Dao) FindPblFolderIdsByMember(userId string, groupIds []string) ([]string, error) {
+	folders := []*m.Folder{}
+	if err := dao.PblFolderQuery().All(&folders); err != nil {
+		return nil, err
+	}
+
+	byId := make(map[string]*m.Folder, len(folders))
+	for _, f := range folders {
+		byId[f.Id] = f
+	}
+
+	result := []string{}
+	for _, f := range folders {
+		visited := map[string]bool{}
+		for current := f; current != nil && !visited[current.Id]; current = byId[current.ParentId.String] {
+			visited[current.Id] = true
+			if current.HasMember(userId, groupIds) {
+				result = append(result, f.Id)
+				break
+			}
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,33 @@
+package daos
+
+import (
+	m "github.com/pedrozadotdev/pocketblocks/server/models"
+	"github.com/pocketbase/dbx"
+)
+
+func (dao *Dao) PblLibraryQueryQuery() *dbx.SelectQuery {
+	return dao.ModelQuery(&m.LibraryQuery{})
+}
+
+func (dao *Dao) FindPblLibraryQueryById(id string) (*m.LibraryQuery, error) {
+	model := &m.LibraryQuery{}
+
+	err := dao.PblLibraryQueryQuery().
+		AndWhere(dbx.HashExp{"id": id}).
+		Limit(1).
+		One(model)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
+
+func (dao *Dao) DeletePblLibraryQuery(libraryQuery *m.LibraryQuery) error {
+	return dao.Delete(libraryQuery)
+}
+
+func (dao *Dao) SavePblLibraryQuery(libraryQuery *m.LibraryQuery) error {
+	return dao.Save(libraryQuery)
+}
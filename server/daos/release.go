@@ -0,0 +1,48 @@
+package daos
+
+import (
+	m "github.com/pedrozadotdev/pocketblocks/server/models"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func (dao *Dao) PblReleaseQuery() *dbx.SelectQuery {
+	return dao.ModelQuery(&m.Release{})
+}
+
+func (dao *Dao) FindPblReleaseById(id string) (*m.Release, error) {
+	model := &m.Release{}
+
+	err := dao.PblReleaseQuery().
+		AndWhere(dbx.HashExp{"id": id}).
+		Limit(1).
+		One(model)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
+
+// FindPblDueReleases returns the approved releases whose scheduled time
+// has come (oldest first).
+func (dao *Dao) FindPblDueReleases(now types.DateTime) ([]*m.Release, error) {
+	releases := []*m.Release{}
+
+	err := dao.PblReleaseQuery().
+		AndWhere(dbx.HashExp{"status": m.ReleaseStatusApproved}).
+		AndWhere(dbx.NewExp("[[scheduledAt]] <= {:now}", dbx.Params{"now": now.String()})).
+		OrderBy("scheduledAt ASC", "created ASC").
+		All(&releases)
+
+	return releases, err
+}
+
+func (dao *Dao) DeletePblRelease(release *m.Release) error {
+	return dao.Delete(release)
+}
+
+func (dao *Dao) SavePblRelease(release *m.Release) error {
+	return dao.Save(release)
+}
@@ -1,8 +1,11 @@
 package daos
 
 import (
+	"time"
+
 	m "github.com/pedrozadotdev/pocketblocks/server/models"
 	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/list"
 )
 
 func (dao *Dao) PblSnapshotQuery() *dbx.SelectQuery {
@@ -28,6 +31,71 @@ func (dao *Dao) DeletePblSnapshot(snapshot *m.Snapshot) error {
 	return dao.Delete(snapshot)
 }
 
+// SavePblSnapshot persists the snapshot with its dsl compressed
+// (the model itself keeps the plain dsl).
 func (dao *Dao) SavePblSnapshot(snapshot *m.Snapshot) error {
+	plainDsl := snapshot.Dsl
+
+	compressed, err := m.CompressSnapshotDsl(plainDsl)
+	if err != nil {
+		return err
+	}
+	snapshot.Dsl = compressed
+	snapshot.Encoding = m.SnapshotEncodingGzip
+
+	defer func() {
+		snapshot.Dsl = plainDsl
+		snapshot.Encoding = ""
+	}()
+
 	return dao.Save(snapshot)
 }
+
+// PblSnapshotPruneResult lists the snapshots removed from a single app.
+type PblSnapshotPruneResult struct {
+	AppId   string   `json:"app"`
+	Removed []string `json:"removed"`
+}
+
+// PrunePblSnapshots removes the snapshots of every app that are not covered
+// by the provided retention policy anymore.
+//
+// Only the apps with removed snapshots are reported.
+func (dao *Dao) PrunePblSnapshots(retention m.SnapshotRetention, now time.Time) ([]*PblSnapshotPruneResult, error) {
+	appIds := []string{}
+	if err := dao.PblSnapshotQuery().Select("app").Distinct(true).Column(&appIds); err != nil {
+		return nil, err
+	}
+
+	results := []*PblSnapshotPruneResult{}
+	for _, appId := range appIds {
+		snapshots := []*m.Snapshot{}
+		if err := dao.PblSnapshotQuery().
+			Select("id", "app", "created", "updated").
+			AndWhere(dbx.HashExp{"app": appId}).
+			All(&snapshots); err != nil {
+			return results, err
+		}
+
+		expired := retention.SelectExpired(snapshots, now)
+		if len(expired) == 0 {
+			continue
+		}
+
+		result := &PblSnapshotPruneResult{AppId: appId, Removed: make([]string, 0, len(expired))}
+		for _, s := range expired {
+			result.Removed = append(result.Removed, s.Id)
+		}
+
+		if _, err := dao.DB().Delete(
+			(&m.Snapshot{}).TableName(),
+			dbx.In("id", list.ToInterfaceSlice(result.Removed)...),
+		).Execute(); err != nil {
+			return results, err
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,141 @@
+// Package datasources executes application queries server-side against
+// the datasources registered in PocketBlocks.
+//
+// Example usage:
+//
+//	query, err := datasources.FindQuery(app.AppDsl, "query1")
+//	...
+//	result, err := datasources.Execute(ctx, dao, datasource, query, params)
+package datasources
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/pedrozadotdev/pocketblocks/server/daos"
+	"github.com/pedrozadotdev/pocketblocks/server/models"
+)
+
+// ErrQueryNotFound is returned when the requested query is not part of the DSL.
+var ErrQueryNotFound = errors.New("query not found")
+
+// CompTypeLibraryQuery is the compType of the app queries referencing a library query.
+const CompTypeLibraryQuery = "libraryQuery"
+
+// Query is a single query definition stored in the "queries" key of an app DSL.
+type Query struct {
+	Id           string         `json:"id"`
+	Name         string         `json:"name"`
+	DatasourceId string         `json:"datasourceId"`
+	CompType     string         `json:"compType"`
+	Comp         map[string]any `json:"comp"`
+}
+
+// FindQuery looks up a query by its id or name in the provided DSL.
+func FindQuery(dsl string, idOrName string) (*Query, error) {
+	var parsed struct {
+		Queries []*Query `json:"queries"`
+	}
+	if err := json.Unmarshal([]byte(dsl), &parsed); err != nil {
+		return nil, err
+	}
+
+	for _, q := range parsed.Queries {
+		if q != nil && (q.Id == idOrName || q.Name == idOrName) {
+			return q, nil
+		}
+	}
+
+	return nil, ErrQueryNotFound
+}
+
+// LibraryQueryId returns the id of the library query referenced by
+// the query (or "" if it's a regular query).
+func (q *Query) LibraryQueryId() string {
+	if q.CompType != CompTypeLibraryQuery {
+		return ""
+	}
+	return compString(q, "libraryQueryId")
+}
+
+// ParseLibraryQuery parses the query stored in a library query DSL.
+func ParseLibraryQuery(dsl string) (*Query, error) {
+	var parsed struct {
+		Query *Query `json:"query"`
+	}
+	if err := json.Unmarshal([]byte(dsl), &parsed); err != nil {
+		return nil, err
+	}
+
+	if parsed.Query == nil {
+		return nil, ErrQueryNotFound
+	}
+
+	return parsed.Query, nil
+}
+
+// Execute runs the query against the provided datasource resolving
+// the query templates with params.
+func Execute(ctx context.Context, dao *daos.Dao, ds *models.Datasource, query *Query, params Params) (any, error) {
+	if query.CompType != ds.Type {
+		return nil, fmt.Errorf("query type %q doesn't match the datasource type %q", query.CompType, ds.Type)
+	}
+
+	switch ds.Type {
+	case models.DatasourceTypeRestApi:
+		config := &models.RestApiConfig{}
+		if err := ds.DecodeConfig(config); err != nil {
+			return nil, err
+		}
+		return executeRestApi(ctx, config, query, params)
+	case models.DatasourceTypePocketBase:
+		config := &models.PocketBaseConfig{}
+		if err := ds.DecodeConfig(config); err != nil {
+			return nil, err
+		}
+		return executePocketBase(dao, config, query, params)
+	case models.DatasourceTypeSqlite:
+		config := &models.SqliteConfig{}
+		if err := ds.DecodeConfig(config); err != nil {
+			return nil, err
+		}
+		return executeSqlite(ctx, config, query, params)
+	default:
+		return nil, fmt.Errorf("unsupported datasource type %q", ds.Type)
+	}
+}
+
+// compString returns the string value of the query comp key (or "").
+func compString(query *Query, key string) string {
+	v, _ := query.Comp[key].(string)
+	return v
+}
+
+// compInt returns the numeric value of the query comp key (or 0).
+func compInt(query *Query, key string) int {
+	switch v := query.Comp[key].(type) {
+	case float64:
+		return int(v)
+	case string:
+		var i int
+		fmt.Sscanf(v, "%d", &i)
+		return i
+	default:
+		return 0
+	}
+}
+
+// compKeyValues returns the key/value list stored in the query comp key.
+func compKeyValues(query *Query, key string) []models.KeyValue {
+	result := []models.KeyValue{}
+
+	raw, err := json.Marshal(query.Comp[key])
+	if err != nil {
+		return result
+	}
+	json.Unmarshal(raw, &result)
+
+	return result
+}
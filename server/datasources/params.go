@@ -0,0 +1,137 @@
+package datasources
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pocketbase/dbx"
+)
+
+var templateRegex = regexp.MustCompile(`{{(.*?)}}`)
+
+// Params holds the evaluated values of the query templates keyed by
+// their normalized expression (eg. "input1.value").
+type Params map[string]any
+
+// Property is a single evaluated template sent by the openblocks client.
+type Property struct {
+	Key   string `json:"key"`
+	Value any    `json:"value"`
+}
+
+// NewParams creates Params from the list of properties sent by the client.
+func NewParams(props []Property) Params {
+	result := Params{}
+	for _, p := range props {
+		result[normalizeExpr(p.Key)] = p.Value
+	}
+	return result
+}
+
+func normalizeExpr(expr string) string {
+	expr = strings.TrimSpace(expr)
+	if strings.HasPrefix(expr, "{{") && strings.HasSuffix(expr, "}}") {
+		expr = strings.TrimSpace(expr[2 : len(expr)-2])
+	}
+	return expr
+}
+
+// Render replaces every template in str with its plain text value.
+func (p Params) Render(str string) string {
+	return p.RenderEscaped(str, nil)
+}
+
+// RenderEscaped replaces every template in str with its text value
+// escaped with the provided escape func (eg. [url.PathEscape]).
+func (p Params) RenderEscaped(str string, escape func(string) string) string {
+	return templateRegex.ReplaceAllStringFunc(str, func(match string) string {
+		v, ok := p[normalizeExpr(match)]
+		if !ok || v == nil {
+			return ""
+		}
+		s := stringValue(v)
+		if escape != nil {
+			s = escape(s)
+		}
+		return s
+	})
+}
+
+// RenderJson replaces every template in the json str with its json encoded value.
+//
+// The templates inside a json string literal (eg. `"{{input1.value}}"`) are
+// replaced with the escaped string content, the others with the raw json value
+// (eg. `{"ids": {{table1.selectedRows}}}`).
+func (p Params) RenderJson(str string) string {
+	var sb strings.Builder
+
+	inString := false
+	last := 0
+	for _, loc := range templateRegex.FindAllStringIndex(str, -1) {
+		inString = jsonStringState(str[last:loc[0]], inString)
+		sb.WriteString(str[last:loc[0]])
+
+		v := p[normalizeExpr(str[loc[0]:loc[1]])]
+		if inString {
+			encoded, _ := json.Marshal(stringValue(v))
+			sb.Write(encoded[1 : len(encoded)-1])
+		} else {
+			encoded, _ := json.Marshal(v)
+			sb.Write(encoded)
+		}
+
+		last = loc[1]
+	}
+	sb.WriteString(str[last:])
+
+	return sb.String()
+}
+
+// jsonStringState returns whether the end of the json fragment is inside
+// a string literal, given whether its start was.
+func jsonStringState(fragment string, inString bool) bool {
+	escaped := false
+	for i := 0; i < len(fragment); i++ {
+		switch {
+		case escaped:
+			escaped = false
+		case inString && fragment[i] == '\\':
+			escaped = true
+		case fragment[i] == '"':
+			inString = !inString
+		}
+	}
+	return inString
+}
+
+// stringValue returns the text value of a param
+// (non string values are json encoded).
+func stringValue(v any) string {
+	if v == nil {
+		return ""
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(encoded)
+}
+
+// Bind replaces every template in str with a `{:pN}` placeholder and
+// returns the values to bind, so they never get interpolated in the raw statement.
+func (p Params) Bind(str string) (string, dbx.Params) {
+	bound := dbx.Params{}
+
+	result := templateRegex.ReplaceAllStringFunc(str, func(match string) string {
+		name := fmt.Sprintf("p%d", len(bound))
+		bound[name] = p[normalizeExpr(match)]
+		return "{:" + name + "}"
+	})
+
+	return result, bound
+}
@@ -0,0 +1,74 @@
+package datasources
+
+import "testing"
+
+func TestParamsRender(t *testing.T) {
+	params := NewParams([]Property{
+		{Key: "{{input1.value}}", Value: "abc"},
+		{Key: "{{ table1.selectedRow.id }}", Value: 10.0},
+		{Key: "{{nullable}}", Value: nil},
+		{Key: "{{obj}}", Value: map[string]any{"a": 1.0}},
+	})
+
+	scenarios := []struct {
+		str      string
+		expected string
+	}{
+		{"", ""},
+		{"{{obj}}", `{"a":1}`},
+		{"/users", "/users"},
+		{"/users/{{input1.value}}", "/users/abc"},
+		{"{{table1.selectedRow.id}}-{{ input1.value }}", "10-abc"},
+		{"{{nullable}}{{missing}}", ""},
+	}
+
+	for i, s := range scenarios {
+		result := params.Render(s.str)
+		if result != s.expected {
+			t.Fatalf("[%d] Expected %q, got %q", i, s.expected, result)
+		}
+	}
+}
+
+func TestParamsRenderJson(t *testing.T) {
+	params := NewParams([]Property{
+		{Key: "{{name}}", Value: `a", "admin": true, "b": "}`},
+		{Key: "{{ids}}", Value: []any{1.0, 2.0}},
+		{Key: "{{count}}", Value: 3.0},
+	})
+
+	scenarios := []struct {
+		str      string
+		expected string
+	}{
+		{"", ""},
+		{`{"name": "{{name}}"}`, `{"name": "a\", \"admin\": true, \"b\": \"}"}`},
+		{`{"name": {{name}}}`, `{"name": "a\", \"admin\": true, \"b\": \"}"}`},
+		{`{"ids": {{ids}}, "label": "ids: {{ids}}"}`, `{"ids": [1,2], "label": "ids: [1,2]"}`},
+		{`{"q": "\"{{count}}\"", "n": {{count}}, "m": {{missing}}}`, `{"q": "\"3\"", "n": 3, "m": null}`},
+	}
+
+	for i, s := range scenarios {
+		result := params.RenderJson(s.str)
+		if result != s.expected {
+			t.Fatalf("[%d] Expected %s, got %s", i, s.expected, result)
+		}
+	}
+}
+
+func TestParamsBind(t *testing.T) {
+	params := NewParams([]Property{
+		{Key: "{{input1.value}}", Value: "' OR 1=1 --"},
+	})
+
+	sql, bound := params.Bind("SELECT * FROM users WHERE name = {{input1.value}} AND id = {{missing}}")
+
+	expectedSql := "SELECT * FROM users WHERE name = {:p0} AND id = {:p1}"
+	if sql != expectedSql {
+		t.Fatalf("Expected %q, got %q", expectedSql, sql)
+	}
+
+	if len(bound) != 2 || bound["p0"] != "' OR 1=1 --" || bound["p1"] != nil {
+		t.Fatalf("Unexpected bound params %v", bound)
+	}
+}
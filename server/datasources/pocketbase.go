@@ -0,0 +1,53 @@
+package datasources
+
+import (
+	"github.com/pedrozadotdev/pocketblocks/server/daos"
+	"github.com/pedrozadotdev/pocketblocks/server/models"
+)
+
+// executePocketBase lists (or views) records of the datasource collection.
+//
+// Datasources are configured by admins so the collection API rules are not applied,
+// but hidden fields are never exported.
+func executePocketBase(dao *daos.Dao, config *models.PocketBaseConfig, query *Query, params Params) (any, error) {
+	if recordId := params.Render(compString(query, "recordId")); recordId != "" {
+		record, err := dao.FindRecordById(config.Collection, recordId)
+		if err != nil {
+			return nil, err
+		}
+		return record.PublicExport(), nil
+	}
+
+	filter, bound := params.Bind(compString(query, "filter"))
+	if filter == "" {
+		filter = "id != ''"
+	}
+
+	limit := compInt(query, "perPage")
+	if limit <= 0 {
+		limit = 30
+	}
+	page := compInt(query, "page")
+	if page <= 0 {
+		page = 1
+	}
+
+	records, err := dao.FindRecordsByFilter(
+		config.Collection,
+		filter,
+		compString(query, "sort"),
+		limit,
+		(page-1)*limit,
+		bound,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]map[string]any, 0, len(records))
+	for _, r := range records {
+		result = append(result, r.PublicExport())
+	}
+
+	return result, nil
+}
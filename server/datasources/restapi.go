@@ -0,0 +1,136 @@
+package datasources
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pedrozadotdev/pocketblocks/server/models"
+)
+
+// HttpClient is a base HTTP client interface (usually used for test purposes).
+type HttpClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// RestApiClient is the client used to execute REST API queries.
+var RestApiClient HttpClient = http.DefaultClient
+
+func executeRestApi(ctx context.Context, config *models.RestApiConfig, query *Query, params Params) (any, error) {
+	method := strings.ToUpper(compString(query, "httpMethod"))
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	u, err := restApiUrl(config.Url, compString(query, "path"), params)
+	if err != nil {
+		return nil, err
+	}
+
+	q := u.Query()
+	for _, kv := range append(config.Params, compKeyValues(query, "params")...) {
+		if kv.Key != "" {
+			q.Add(params.Render(kv.Key), params.Render(kv.Value))
+		}
+	}
+	u.RawQuery = q.Encode()
+
+	header := http.Header{}
+	for _, kv := range append(config.Headers, compKeyValues(query, "headers")...) {
+		if kv.Key != "" {
+			header.Set(params.Render(kv.Key), params.Render(kv.Value))
+		}
+	}
+
+	var body io.Reader
+	if rawBody := compString(query, "body"); rawBody != "" && method != http.MethodGet {
+		if header.Get("Content-Type") == "" {
+			header.Set("Content-Type", "application/json")
+		}
+
+		// json values are encoded, so they can't break out of the body template
+		if strings.Contains(header.Get("Content-Type"), "json") {
+			body = strings.NewReader(params.RenderJson(rawBody))
+		} else {
+			body = strings.NewReader(params.Render(rawBody))
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = header
+
+	resp, err := RestApiClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var result any
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		// not a json response
+		return string(respBody), nil
+	}
+
+	return result, nil
+}
+
+// restApiUrl joins the rendered path template (with an optional query string)
+// to the configured base url.
+//
+// The param values are escaped so they can't change the host or
+// reach a path outside of the base url one.
+func restApiUrl(baseUrl string, pathTemplate string, params Params) (*url.URL, error) {
+	base, err := url.Parse(baseUrl)
+	if err != nil {
+		return nil, err
+	}
+	if (base.Scheme != "http" && base.Scheme != "https") || base.Host == "" {
+		return nil, errors.New("the datasource url must be an absolute http(s) url")
+	}
+	if base.Path == "" {
+		base.Path = "/"
+	}
+
+	pathTemplate, queryTemplate, _ := strings.Cut(pathTemplate, "?")
+
+	u := base.JoinPath(params.RenderEscaped(pathTemplate, url.PathEscape))
+
+	basePath := strings.TrimSuffix(base.EscapedPath(), "/")
+	if u.Scheme != base.Scheme || u.Host != base.Host ||
+		(u.EscapedPath() != basePath && !strings.HasPrefix(u.EscapedPath(), basePath+"/")) {
+		return nil, errors.New("the query path must stay within the datasource url")
+	}
+
+	if queryTemplate != "" {
+		extra, err := url.ParseQuery(params.RenderEscaped(queryTemplate, url.QueryEscape))
+		if err != nil {
+			return nil, err
+		}
+		q := u.Query()
+		for k, values := range extra {
+			for _, v := range values {
+				q.Add(k, v)
+			}
+		}
+		u.RawQuery = q.Encode()
+	}
+
+	return u, nil
+}
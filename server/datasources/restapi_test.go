@@ -0,0 +1,50 @@
+package datasources
+
+import (
+	"testing"
+)
+
+func TestRestApiUrl(t *testing.T) {
+	params := NewParams([]Property{
+		{Key: "{{id}}", Value: "a b"},
+		{Key: "{{host}}", Value: "@evil.com"},
+		{Key: "{{domain}}", Value: ".evil.com"},
+		{Key: "{{parent}}", Value: "../admin"},
+		{Key: "{{dots}}", Value: ".."},
+		{Key: "{{q}}", Value: "x&y=1"},
+	})
+
+	scenarios := []struct {
+		base        string
+		path        string
+		expected    string
+		expectError bool
+	}{
+		{"https://api.example.com/v1/", "", "https://api.example.com/v1", false},
+		{"https://api.example.com/v1", "/users/{{id}}", "https://api.example.com/v1/users/a%20b", false},
+		{"https://api.example.com/v1?key=1", "/users?search={{q}}", "https://api.example.com/v1/users?key=1&search=x%26y%3D1", false},
+		{"https://api.example.com", "{{host}}", "https://api.example.com/@evil.com", false},
+		{"https://api.example.com", "{{domain}}/users", "https://api.example.com/.evil.com/users", false},
+		{"https://api.example.com/v1", "/users/{{parent}}", "https://api.example.com/v1/users/..%2Fadmin", false},
+		{"https://api.example.com/v1", "/{{dots}}/admin", "", true},
+		{"https://api.example.com/v1", "/../admin", "", true},
+		{"api.example.com", "/users", "", true},
+		{"file:///etc/passwd", "", "", true},
+	}
+
+	for i, s := range scenarios {
+		u, err := restApiUrl(s.base, s.path, params)
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Fatalf("[%d] Expected hasErr %v, got %v (%v)", i, s.expectError, hasErr, err)
+		}
+		if hasErr {
+			continue
+		}
+
+		if u.String() != s.expected {
+			t.Fatalf("[%d] Expected %q, got %q", i, s.expected, u.String())
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package datasources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/pedrozadotdev/pocketblocks/server/models"
+)
+
+// sqliteForbiddenKeywords are the statements able to reach other database
+// files (or change the connection) regardless of the read only mode,
+// which only applies to the main database.
+var sqliteForbiddenKeywords = []string{"ATTACH", "DETACH", "PRAGMA", "VACUUM"}
+
+func executeSqlite(ctx context.Context, config *models.SqliteConfig, query *Query, params Params) (any, error) {
+	sql, bound := params.Bind(compString(query, "sql"))
+	if sql == "" {
+		return nil, errors.New("missing sql statement")
+	}
+	if err := checkSqliteStatement(sql); err != nil {
+		return nil, err
+	}
+
+	dsn := "file:" + config.Path
+	if config.ReadOnly {
+		dsn += "?mode=ro"
+	}
+
+	db, err := connectSqlite(dsn)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.NewQuery(sql).Bind(bound).WithContext(ctx).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	result := []map[string]any{}
+	for rows.Next() {
+		values := make([]any, len(columns))
+		pointers := make([]any, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+
+		row := make(map[string]any, len(columns))
+		for i, col := range columns {
+			if b, ok := values[i].([]byte); ok {
+				row[col] = string(b)
+			} else {
+				row[col] = values[i]
+			}
+		}
+		result = append(result, row)
+	}
+
+	return result, rows.Err()
+}
+
+// checkSqliteStatement rejects the sql statements using one of the
+// [sqliteForbiddenKeywords] (string literals, quoted identifiers and
+// comments are skipped).
+func checkSqliteStatement(sql string) error {
+	for i := 0; i < len(sql); {
+		ch := sql[i]
+
+		switch {
+		case ch == '\'' || ch == '"' || ch == '`' || ch == '[':
+			end := ch
+			if ch == '[' {
+				end = ']'
+			}
+			next := strings.IndexByte(sql[i+1:], end)
+			if next < 0 {
+				return nil // unterminated, the statement won't compile anyway
+			}
+			i += next + 2
+		case strings.HasPrefix(sql[i:], "--"):
+			next := strings.IndexByte(sql[i:], '\n')
+			if next < 0 {
+				return nil
+			}
+			i += next + 1
+		case strings.HasPrefix(sql[i:], "/*"):
+			next := strings.Index(sql[i+2:], "*/")
+			if next < 0 {
+				return nil
+			}
+			i += next + 4
+		case isSqliteWordChar(ch):
+			start := i
+			for i < len(sql) && isSqliteWordChar(sql[i]) {
+				i++
+			}
+			word := sql[start:i]
+			for _, keyword := range sqliteForbiddenKeywords {
+				if strings.EqualFold(word, keyword) {
+					return fmt.Errorf("%s statements are not allowed", keyword)
+				}
+			}
+		default:
+			i++
+		}
+	}
+
+	return nil
+}
+
+func isSqliteWordChar(ch byte) bool {
+	return ch == '_' || ch == '$' ||
+		(ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9') ||
+		ch >= 0x80
+}
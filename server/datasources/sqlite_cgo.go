@@ -0,0 +1,12 @@
+//go:build cgo
+
+package datasources
+
+import (
+	"github.com/pocketbase/dbx"
+	_ "github.com/pocketbase/pocketbase/core" // registers the pb_sqlite3 driver
+)
+
+func connectSqlite(dsn string) (*dbx.DB, error) {
+	return dbx.Open("pb_sqlite3", dsn)
+}
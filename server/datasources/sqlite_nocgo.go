@@ -0,0 +1,12 @@
+//go:build !cgo
+
+package datasources
+
+import (
+	"github.com/pocketbase/dbx"
+	_ "modernc.org/sqlite"
+)
+
+func connectSqlite(dsn string) (*dbx.DB, error) {
+	return dbx.Open("sqlite", dsn)
+}
@@ -0,0 +1,27 @@
+package datasources
+
+import "testing"
+
+func TestCheckSqliteStatement(t *testing.T) {
+	scenarios := []struct {
+		sql         string
+		expectError bool
+	}{
+		{"SELECT * FROM users", false},
+		{"SELECT 'attach' AS [pragma], \"vacuum\" FROM t -- detach\n", false},
+		{"SELECT * FROM pragma_table_info('users') /* PRAGMA */", false},
+		{"SELECT attached_at FROM logs", false},
+		{"ATTACH 'pb_data/data.db' AS x", true},
+		{"attach database 'test.db' as x", true},
+		{"SELECT 1; PRAGMA writable_schema = ON", true},
+		{"SELECT 1; /* x */ Detach x", true},
+		{"VACUUM INTO '/tmp/copy.db'", true},
+	}
+
+	for i, s := range scenarios {
+		err := checkSqliteStatement(s.sql)
+		if s.expectError != (err != nil) {
+			t.Fatalf("[%d] Expected error %v, got %v", i, s.expectError, err)
+		}
+	}
+}
@@ -0,0 +1,400 @@
+// Package dslcheck validates the page DSLs generated by the AI assistant
+// against a component catalog.
+//
+// Example usage:
+//
+//	catalog := dslcheck.ParseCatalog(prompt)
+//	report := dslcheck.Validate(rawDsl, catalog)
+//	if !report.Valid {
+//		fmt.Println(report.Prompt())
+//	}
+package dslcheck
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// GridColumns is the number of columns of the page layout grid.
+const GridColumns = 24
+
+// catalogSection is the prompt heading of the component catalog.
+const catalogSection = "## Available Component Types"
+
+var catalogItemRegex = regexp.MustCompile(`^-\s+"([A-Za-z0-9_]+)"`)
+
+// Catalog is the set of the known component types.
+type Catalog map[string]struct{}
+
+// ParseCatalog extracts the component types listed in the
+// "## Available Component Types" section of prompt
+// (one `- "compType" - description` line per type).
+func ParseCatalog(prompt string) Catalog {
+	catalog := Catalog{}
+
+	_, section, found := strings.Cut(prompt, catalogSection)
+	if !found {
+		return catalog
+	}
+
+	for _, line := range strings.Split(section, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "## ") {
+			break // next section
+		}
+		if m := catalogItemRegex.FindStringSubmatch(line); m != nil {
+			catalog[m[1]] = struct{}{}
+		}
+	}
+
+	return catalog
+}
+
+// Has checks whether compType is part of the catalog.
+func (c Catalog) Has(compType string) bool {
+	_, ok := c[compType]
+	return ok
+}
+
+// Issue is a single validation problem found at a DSL path
+// (eg. "ui.comp.table1.compType").
+type Issue struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// Report is the result of a DSL validation.
+//
+// Only the errors make a DSL invalid, the warnings are informative.
+type Report struct {
+	Valid    bool     `json:"valid"`
+	Errors   []*Issue `json:"errors"`
+	Warnings []*Issue `json:"warnings"`
+}
+
+func (r *Report) addError(path string, format string, args ...any) {
+	r.Errors = append(r.Errors, &Issue{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (r *Report) addWarning(path string, format string, args ...any) {
+	r.Warnings = append(r.Warnings, &Issue{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// Prompt lists the report errors, one per line, in a form
+// suitable to be sent back to the model.
+func (r *Report) Prompt() string {
+	var b strings.Builder
+	for _, e := range r.Errors {
+		b.WriteString("- ")
+		if e.Path != "" {
+			b.WriteString(e.Path)
+			b.WriteString(": ")
+		}
+		b.WriteString(e.Message)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// Validate checks the raw page DSL against the catalog.
+//
+// It reports the invalid JSON, the duplicated keys, the unknown component
+// types, the duplicated component/query names and the layout entries
+// without a matching component.
+//
+// Both the containers with components keyed by name ({"comp": ..., "layout": ...})
+// and the editor ones with components keyed by id ({"items": ..., "layout": ...})
+// are supported.
+func Validate(raw []byte, catalog Catalog) *Report {
+	report := &Report{Errors: []*Issue{}, Warnings: []*Issue{}}
+	defer func() {
+		report.Valid = len(report.Errors) == 0
+	}()
+
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || string(raw) == "null" {
+		report.addError("dsl", "the dsl is missing")
+		return report
+	}
+
+	// the duplicated keys are silently dropped once decoded
+	if err := checkDuplicateKeys(json.NewDecoder(bytes.NewReader(raw)), "", report); err != nil {
+		report.addError("dsl", "invalid JSON: %v", err)
+		return report
+	}
+
+	var dsl map[string]any
+	if err := json.Unmarshal(raw, &dsl); err != nil {
+		report.addError("dsl", "the dsl must be a JSON object")
+		return report
+	}
+
+	ui, ok := dsl["ui"].(map[string]any)
+	if !ok {
+		report.addError("ui", "the ui object is missing")
+		return report
+	}
+
+	names := map[string]string{}
+	checkContainer(ui, "ui", catalog, names, report)
+
+	checkName := func(name string, path string) {
+		if other, ok := names[name]; ok {
+			report.addError(path, "the name %q is already used by %s", name, other)
+			return
+		}
+		names[name] = path
+	}
+
+	switch queries := dsl["queries"].(type) {
+	case map[string]any:
+		for _, name := range slices.Sorted(maps.Keys(queries)) {
+			checkName(name, "queries."+name)
+		}
+	case []any:
+		// editor dsl
+		for i, q := range queries {
+			query, _ := q.(map[string]any)
+			if name, _ := query["name"].(string); name != "" {
+				checkName(name, fmt.Sprintf("queries[%d]", i))
+			}
+		}
+	}
+
+	return report
+}
+
+// ValidateChanges checks the raw page DSL like [Validate], but only reports
+// the errors that aren't already in the base DSL (eg. the page edited by a patch).
+func ValidateChanges(raw []byte, base []byte, catalog Catalog) *Report {
+	report := Validate(raw, catalog)
+
+	existing := map[Issue]bool{}
+	for _, e := range Validate(base, catalog).Errors {
+		existing[*e] = true
+	}
+
+	errors := []*Issue{}
+	for _, e := range report.Errors {
+		if !existing[*e] {
+			errors = append(errors, e)
+		}
+	}
+	report.Errors = errors
+	report.Valid = len(errors) == 0
+
+	return report
+}
+
+// checkContainer validates the components of a container node
+// (a {"comp": {...}, "layout": ...} or {"items": {...}, "layout": ...} object)
+// and its nested containers.
+func checkContainer(node map[string]any, path string, catalog Catalog, names map[string]string, report *Report) {
+	compsKey := containerKey(node)
+	comps, _ := node[compsKey].(map[string]any)
+
+	for _, key := range slices.Sorted(maps.Keys(comps)) {
+		compPath := path + "." + compsKey + "." + key
+
+		comp, ok := comps[key].(map[string]any)
+		if !ok {
+			report.addError(compPath, "the component must be an object")
+			continue
+		}
+
+		// the editor items are keyed by id
+		name := key
+		if n, _ := comp["name"].(string); compsKey == "items" && n != "" {
+			name = n
+		}
+
+		if other, ok := names[name]; ok {
+			report.addError(compPath, "the name %q is already used by %s", name, other)
+		} else {
+			names[name] = compPath
+		}
+
+		compType, _ := comp["compType"].(string)
+		switch {
+		case compType == "":
+			report.addError(compPath+".compType", "the compType is missing")
+		case !catalog.Has(compType):
+			report.addError(compPath+".compType", "unknown compType %q", compType)
+		}
+
+		for childPath, child := range nestedContainers(comp["comp"], compPath+".comp") {
+			checkContainer(child, childPath, catalog, names, report)
+		}
+	}
+
+	checkLayout(node["layout"], path+".layout", comps, report)
+}
+
+// nestedContainers finds the container nodes in the props of a component.
+func nestedContainers(value any, path string) map[string]map[string]any {
+	result := map[string]map[string]any{}
+
+	var walk func(v any, p string)
+	walk = func(v any, p string) {
+		switch v := v.(type) {
+		case map[string]any:
+			if isContainer(v) {
+				result[p] = v
+				return
+			}
+			for k, child := range v {
+				walk(child, p+"."+k)
+			}
+		case []any:
+			for i, child := range v {
+				walk(child, fmt.Sprintf("%s[%d]", p, i))
+			}
+		}
+	}
+	walk(value, path)
+
+	return result
+}
+
+// containerKey returns the key of the container node child components
+// ("items" for the editor containers, "comp" otherwise).
+func containerKey(node map[string]any) string {
+	if _, ok := node["items"].(map[string]any); ok {
+		return "items"
+	}
+	return "comp"
+}
+
+// isContainer checks whether node holds child components
+// (a "comp" or "items" object whose values all have a compType).
+func isContainer(node map[string]any) bool {
+	comps, ok := node[containerKey(node)].(map[string]any)
+	if !ok || len(comps) == 0 {
+		return false
+	}
+	for _, c := range comps {
+		comp, ok := c.(map[string]any)
+		if !ok {
+			return false
+		}
+		if _, ok := comp["compType"]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// checkLayout validates the layout entries (a list or an object keyed
+// by component name) of a container against its components.
+func checkLayout(layout any, path string, comps map[string]any, report *Report) {
+	entries := map[string]map[string]any{}
+
+	addEntry := func(entryPath string, key string, v any) {
+		entry, ok := v.(map[string]any)
+		if !ok {
+			report.addError(entryPath, "the layout entry must be an object")
+			return
+		}
+		if i, ok := entry["i"].(string); ok && i != "" {
+			key = i
+		}
+		if key == "" {
+			report.addError(entryPath, "the layout entry has no component key (i)")
+			return
+		}
+		if _, ok := entries[key]; ok {
+			report.addError(entryPath, "duplicated layout entry for %q", key)
+			return
+		}
+		entries[key] = entry
+	}
+
+	switch layout := layout.(type) {
+	case nil:
+	case []any:
+		for i, v := range layout {
+			addEntry(fmt.Sprintf("%s[%d]", path, i), "", v)
+		}
+	case map[string]any:
+		for _, k := range slices.Sorted(maps.Keys(layout)) {
+			addEntry(path+"."+k, k, layout[k])
+		}
+	default:
+		report.addError(path, "the layout must be a list or an object")
+		return
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(entries)) {
+		entryPath := path + "." + key
+		if _, ok := comps[key]; !ok {
+			report.addError(entryPath, "the layout entry %q has no matching component", key)
+			continue
+		}
+
+		entry := entries[key]
+		x, _ := entry["x"].(float64)
+		w, _ := entry["w"].(float64)
+		if x < 0 || w < 0 || x+w > GridColumns {
+			report.addWarning(entryPath, "the component overflows the %d columns grid (x=%v, w=%v)", GridColumns, x, w)
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(comps)) {
+		if _, ok := entries[name]; !ok {
+			report.addWarning(path, "the component %q has no layout entry", name)
+		}
+	}
+}
+
+// checkDuplicateKeys reads the next JSON value from dec reporting
+// the duplicated keys of its objects.
+func checkDuplicateKeys(dec *json.Decoder, path string, report *Report) error {
+	token, err := dec.Token()
+	if err != nil {
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+
+	delim, ok := token.(json.Delim)
+	if !ok {
+		return nil
+	}
+
+	switch delim {
+	case '{':
+		keys := map[string]bool{}
+		for dec.More() {
+			token, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			key, _ := token.(string)
+			keyPath := strings.TrimPrefix(path+"."+key, ".")
+			if keys[key] {
+				report.addError(keyPath, "duplicated key %q", key)
+			}
+			keys[key] = true
+			if err := checkDuplicateKeys(dec, keyPath, report); err != nil {
+				return err
+			}
+		}
+	case '[':
+		for i := 0; dec.More(); i++ {
+			if err := checkDuplicateKeys(dec, fmt.Sprintf("%s[%d]", path, i), report); err != nil {
+				return err
+			}
+		}
+	}
+
+	// closing delimiter
+	_, err = dec.Token()
+	return err
+}
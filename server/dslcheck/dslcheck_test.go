@@ -0,0 +1,231 @@
+package dslcheck
+
+import (
+	"strings"
+	"testing"
+)
+
+const testPrompt = `Intro
+
+## Available Component Types
+- "input" - Text input field. Props: value
+- "table" - Data table. Props: data
+- "container" - Generic container for nesting
+
+## Rules
+- "notAType" - ignored since it's in another section
+`
+
+func TestParseCatalog(t *testing.T) {
+	catalog := ParseCatalog(testPrompt)
+
+	if len(catalog) != 3 {
+		t.Fatalf("Expected 3 types, got %v", catalog)
+	}
+	for _, compType := range []string{"input", "table", "container"} {
+		if !catalog.Has(compType) {
+			t.Errorf("Expected %q in the catalog", compType)
+		}
+	}
+	if catalog.Has("notAType") {
+		t.Error("Expected the other sections to be ignored")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	catalog := ParseCatalog(testPrompt)
+
+	scenarios := []struct {
+		name             string
+		dsl              string
+		expectedErrors   []string
+		expectedWarnings []string
+	}{
+		{
+			"missing dsl",
+			`null`,
+			[]string{"dsl: the dsl is missing"},
+			nil,
+		},
+		{
+			"invalid json",
+			`{"ui": {`,
+			[]string{"dsl: invalid JSON"},
+			nil,
+		},
+		{
+			"missing ui",
+			`{"queries": {}}`,
+			[]string{"ui: the ui object is missing"},
+			nil,
+		},
+		{
+			"valid",
+			`{"ui": {"compType": "page", "comp": {
+				"input1": {"compType": "input", "comp": {}},
+				"table1": {"compType": "table", "comp": {}}
+			}, "layout": {
+				"input1": {"i": "input1", "x": 0, "y": 0, "w": 12, "h": 5},
+				"table1": {"i": "table1", "x": 0, "y": 5, "w": 24, "h": 40}
+			}}, "queries": {"query1": {}}}`,
+			nil,
+			nil,
+		},
+		{
+			"unknown compType",
+			`{"ui": {"comp": {"chart1": {"compType": "fancyChart"}}, "layout": {"chart1": {"x": 0, "w": 4}}}}`,
+			[]string{`ui.comp.chart1.compType: unknown compType "fancyChart"`},
+			nil,
+		},
+		{
+			"duplicated keys",
+			`{"ui": {"comp": {"input1": {"compType": "input"}, "input1": {"compType": "input"}}, "layout": {"input1": {"x": 0, "w": 4}}}}`,
+			[]string{`ui.comp.input1: duplicated key "input1"`},
+			nil,
+		},
+		{
+			"duplicated names",
+			`{"ui": {"comp": {
+				"input1": {"compType": "input"},
+				"container1": {"compType": "container", "comp": {"container": {
+					"comp": {"input1": {"compType": "input"}},
+					"layout": [{"i": "input1", "x": 0, "w": 4}]
+				}}}
+			}, "layout": {"input1": {"x": 0, "w": 4}, "container1": {"x": 4, "w": 4}}}, "queries": {"container1": {}}}`,
+			[]string{
+				`ui.comp.input1: the name "input1" is already used by ui.comp.container1.comp.container.comp.input1`,
+				`queries.container1: the name "container1" is already used by ui.comp.container1`,
+			},
+			nil,
+		},
+		{
+			"layout without component",
+			`{"ui": {"comp": {"input1": {"compType": "input"}}, "layout": [
+				{"i": "input1", "x": 20, "w": 8},
+				{"i": "button1", "x": 0, "w": 4}
+			]}}`,
+			[]string{`ui.layout.button1: the layout entry "button1" has no matching component`},
+			[]string{"ui.layout.input1: the component overflows the 24 columns grid"},
+		},
+		{
+			"valid editor dsl",
+			`{"ui": {"layout": {
+				"a1b2": {"i": "a1b2", "x": 0, "y": 0, "w": 12, "h": 5},
+				"c3d4": {"i": "c3d4", "x": 12, "y": 0, "w": 12, "h": 40}
+			}, "items": {
+				"a1b2": {"compType": "input", "name": "input1", "comp": {}},
+				"c3d4": {"compType": "container", "name": "container1", "comp": {"container": {
+					"layout": {"e5f6": {"i": "e5f6", "x": 0, "w": 6}},
+					"items": {"e5f6": {"compType": "table", "name": "table1", "comp": {}}}
+				}}}
+			}}, "queries": [{"id": "q1", "name": "query1"}]}`,
+			nil,
+			nil,
+		},
+		{
+			"invalid editor dsl",
+			`{"ui": {"layout": {
+				"a1b2": {"i": "a1b2", "x": 0, "w": 12},
+				"zzzz": {"i": "zzzz", "x": 0, "w": 12}
+			}, "items": {
+				"a1b2": {"compType": "input", "name": "input1", "comp": {}},
+				"c3d4": {"compType": "fancyChart", "name": "query1", "comp": {}}
+			}}, "queries": [{"id": "q1", "name": "input1"}]}`,
+			[]string{
+				`ui.items.c3d4.compType: unknown compType "fancyChart"`,
+				`ui.layout.zzzz: the layout entry "zzzz" has no matching component`,
+				`queries[0]: the name "input1" is already used by ui.items.a1b2`,
+			},
+			[]string{`ui.layout: the component "c3d4" has no layout entry`},
+		},
+		{
+			"component without layout",
+			`{"ui": {"comp": {"input1": {"compType": "input"}}}}`,
+			nil,
+			[]string{`ui.layout: the component "input1" has no layout entry`},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			report := Validate([]byte(s.dsl), catalog)
+
+			if report.Valid != (len(s.expectedErrors) == 0) {
+				t.Fatalf("Expected valid %v, got %v (%s)", len(s.expectedErrors) == 0, report.Valid, report.Prompt())
+			}
+			assertIssues(t, "error", report.Errors, s.expectedErrors)
+			assertIssues(t, "warning", report.Warnings, s.expectedWarnings)
+		})
+	}
+}
+
+func TestValidateChanges(t *testing.T) {
+	catalog := ParseCatalog(testPrompt)
+
+	base := `{"ui": {"comp": {
+		"input1": {"compType": "input"},
+		"chart1": {"compType": "fancyChart"}
+	}, "layout": {"input1": {"x": 0, "w": 4}, "chart1": {"x": 4, "w": 4}}}}`
+
+	scenarios := []struct {
+		name           string
+		dsl            string
+		expectedErrors []string
+	}{
+		{
+			"existing errors only",
+			`{"ui": {"comp": {
+				"input1": {"compType": "input"},
+				"input2": {"compType": "input"},
+				"chart1": {"compType": "fancyChart"}
+			}, "layout": {"input1": {"x": 0, "w": 4}, "input2": {"x": 0, "w": 4}, "chart1": {"x": 4, "w": 4}}}}`,
+			nil,
+		},
+		{
+			"new errors",
+			`{"ui": {"comp": {
+				"input1": {"compType": "input"},
+				"map1": {"compType": "map"},
+				"chart1": {"compType": "fancyChart"}
+			}, "layout": {"input1": {"x": 0, "w": 4}, "map1": {"x": 0, "w": 4}, "chart1": {"x": 4, "w": 4}}}}`,
+			[]string{`ui.comp.map1.compType: unknown compType "map"`},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			report := ValidateChanges([]byte(s.dsl), []byte(base), catalog)
+
+			if report.Valid != (len(s.expectedErrors) == 0) {
+				t.Fatalf("Expected valid %v, got %v (%s)", len(s.expectedErrors) == 0, report.Valid, report.Prompt())
+			}
+			assertIssues(t, "error", report.Errors, s.expectedErrors)
+		})
+	}
+}
+
+func assertIssues(t *testing.T, kind string, issues []*Issue, expected []string) {
+	t.Helper()
+
+	if len(issues) != len(expected) {
+		t.Fatalf("Expected %d %ss, got %d: %v", len(expected), kind, len(issues), issues)
+	}
+
+	for i, e := range expected {
+		issue := issues[i].Path + ": " + issues[i].Message
+		if !strings.HasPrefix(issue, e) {
+			t.Errorf("Expected %s %d to start with %q, got %q", kind, i, e, issue)
+		}
+	}
+}
+
+func TestReportPrompt(t *testing.T) {
+	report := &Report{Errors: []*Issue{
+		{Path: "ui", Message: "a"},
+		{Message: "b"},
+	}}
+
+	if prompt := report.Prompt(); prompt != "- ui: a\n- b\n" {
+		t.Fatalf("Unexpected prompt %q", prompt)
+	}
+}
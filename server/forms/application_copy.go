@@ -0,0 +1,148 @@
+package forms
+
+import (
+	"maps"
+	"slices"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pedrozadotdev/pocketblocks/server/daos"
+	"github.com/pedrozadotdev/pocketblocks/server/models"
+	"github.com/pocketbase/dbx"
+	pbDaos "github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/tools/list"
+)
+
+// ApplicationCopy is a form that duplicates an application into a new one
+// (with a new name and slug).
+type ApplicationCopy struct {
+	dao        *daos.Dao
+	source     *models.Application
+	memberId   string
+	memberRole string
+
+	Name            string `form:"name" json:"name"`
+	CopyPermissions bool   `form:"copyPermissions" json:"copyPermissions"`
+	CopyFolder      bool   `form:"copyFolder" json:"copyFolder"`
+	CopySnapshots   bool   `form:"copySnapshots" json:"copySnapshots"`
+}
+
+// NewApplicationCopy creates a new [ApplicationCopy] form for the provided
+// source application.
+//
+// If you want to submit the form as part of a transaction,
+// you can change the default Dao via [SetDao()].
+func NewApplicationCopy(dao *daos.Dao, source *models.Application) *ApplicationCopy {
+	return &ApplicationCopy{
+		dao:    dao,
+		source: source,
+	}
+}
+
+// SetDao replaces the default form Dao instance with the provided one.
+func (form *ApplicationCopy) SetDao(dao *daos.Dao) {
+	form.dao = dao
+}
+
+// SetMember grants the provided user (usually the one requesting the copy)
+// at least the provided role on the new application.
+func (form *ApplicationCopy) SetMember(userId string, role string) {
+	form.memberId = userId
+	form.memberRole = role
+}
+
+// Validate makes the form validatable by implementing [validation.Validatable] interface.
+func (form *ApplicationCopy) Validate() error {
+	if form.source.Status == "RECYCLED" {
+		return validation.Errors{
+			"status": validation.NewError("validation_app_recycled", "Recycled applications can't be copied"),
+		}
+	}
+
+	return validation.ValidateStruct(form,
+		validation.Field(&form.Name, validation.Length(0, 255)),
+	)
+}
+
+// Submit validates the form and creates the application copy.
+func (form *ApplicationCopy) Submit() (*models.Application, error) {
+	if err := form.Validate(); err != nil {
+		return nil, err
+	}
+
+	var app *models.Application
+
+	txErr := form.dao.RunInTransaction(func(txDao *pbDaos.Dao) error {
+		dao := daos.New(txDao.DB())
+
+		appForm := NewApplicationUpsert(dao, &models.Application{})
+		appForm.Name = form.Name
+		if appForm.Name == "" {
+			appForm.Name = form.source.Name + " Copy"
+		}
+		appForm.Type = form.source.Type
+		appForm.Status = "NORMAL"
+		appForm.AppDsl = form.source.AppDsl
+		appForm.EditDsl = form.source.EditDsl
+		appForm.Groups = []string{}
+		appForm.Users = []string{}
+		appForm.Roles = map[string]string{}
+
+		if form.CopyFolder {
+			appForm.FolderId = form.source.FolderId.String
+		}
+
+		if form.CopyPermissions {
+			appForm.Public = form.source.Public
+			appForm.AllUsers = form.source.AllUsers
+			appForm.Groups = slices.Clone(form.source.Groups)
+			appForm.Users = slices.Clone(form.source.Users)
+			maps.Copy(appForm.Roles, form.source.Roles)
+		}
+
+		if form.memberId != "" {
+			appForm.Users = list.ToUniqueStringSlice(append(appForm.Users, form.memberId))
+
+			key := form.memberId + "|USER"
+			if current, ok := appForm.Roles[key]; !ok || !models.AppRoleGreaterOrEqual(current, form.memberRole) {
+				appForm.Roles[key] = form.memberRole
+			}
+		}
+
+		var err error
+		if app, err = appForm.Submit(); err != nil {
+			return err
+		}
+
+		if !form.CopySnapshots {
+			return nil
+		}
+
+		snapshots := []*models.Snapshot{}
+		if err := dao.PblSnapshotQuery().
+			AndWhere(dbx.HashExp{"app": form.source.Id}).
+			OrderBy("created ASC").
+			All(&snapshots); err != nil {
+			return err
+		}
+
+		for _, s := range snapshots {
+			snapshot := &models.Snapshot{}
+			snapshot.Created = s.Created
+
+			snapshotForm := NewSnapshotUpsert(dao, snapshot)
+			snapshotForm.AppId = app.Id
+			snapshotForm.Dsl = s.Dsl
+			snapshotForm.Context = s.Context
+			if _, err := snapshotForm.Submit(); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if txErr != nil {
+		return nil, txErr
+	}
+
+	return app, nil
+}
@@ -0,0 +1,118 @@
+// PocketBase v0.22 collection schemas can't be decoded by the json v2
+// backend (SchemaField.UnmarshalJSON recurses forever), so the db backed
+// tests only run with the classic encoding/json implementation.
+
+//go:build !goexperiment.jsonv2
+
+package forms_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/pedrozadotdev/pocketblocks/server/daos"
+	"github.com/pedrozadotdev/pocketblocks/server/forms"
+	_ "github.com/pedrozadotdev/pocketblocks/server/migrations"
+	"github.com/pedrozadotdev/pocketblocks/server/models"
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	pbModels "github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/migrate"
+)
+
+func newTestDao(t *testing.T) *daos.Dao {
+	t.Helper()
+
+	app := core.NewBaseApp(core.BaseAppConfig{DataDir: t.TempDir()})
+	if err := app.Bootstrap(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { app.ResetBootstrapState() })
+
+	runner, err := migrate.NewRunner(app.DB(), m.AppMigrations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runner.Up(); err != nil {
+		t.Fatal(err)
+	}
+
+	return daos.New(app.DB())
+}
+
+func newTestUser(t *testing.T, dao *daos.Dao, email string) string {
+	t.Helper()
+
+	collection, err := dao.FindCollectionByNameOrId("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user := pbModels.NewRecord(collection)
+	user.SetEmail(email)
+	user.SetUsername(strings.Split(email, "@")[0])
+	user.SetPassword("1234567890")
+	user.Set("name", email)
+	if err := dao.SaveRecord(user); err != nil {
+		t.Fatal(err)
+	}
+
+	return user.Id
+}
+
+func TestApplicationCopyKeepsTheMemberRole(t *testing.T) {
+	dao := newTestDao(t)
+	editorId := newTestUser(t, dao, "editor@example.com")
+	ownerId := newTestUser(t, dao, "owner@example.com")
+
+	sourceForm := forms.NewApplicationUpsert(dao, &models.Application{})
+	sourceForm.Name = "Source"
+	sourceForm.Type = 1
+	sourceForm.Status = "NORMAL"
+	sourceForm.AppDsl = "{}"
+	sourceForm.Users = []string{editorId, ownerId}
+	sourceForm.Roles = map[string]string{
+		editorId + "|USER": models.AppRoleEditor,
+		ownerId + "|USER":  models.AppRoleOwner,
+	}
+	source, err := sourceForm.Submit()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name            string
+		copyPermissions bool
+		expectedUsers   []string
+	}{
+		{"without permissions", false, []string{editorId}},
+		{"with permissions", true, []string{editorId, ownerId}},
+	}
+
+	for _, s := range scenarios {
+		form := forms.NewApplicationCopy(dao, source)
+		form.CopyPermissions = s.copyPermissions
+		form.SetMember(editorId, source.MemberRole(editorId+"|USER"))
+
+		copy, err := form.Submit()
+		if err != nil {
+			t.Fatalf("[%s] Unexpected error %v", s.name, err)
+		}
+
+		if copy.Id == source.Id {
+			t.Errorf("[%s] Expected a new application, got the source one", s.name)
+		}
+		for _, id := range s.expectedUsers {
+			if !slices.Contains(copy.Users, id) {
+				t.Errorf("[%s] Expected user %s in %v", s.name, id, copy.Users)
+			}
+		}
+		if len(copy.Users) != len(s.expectedUsers) {
+			t.Errorf("[%s] Expected %d users, got %v", s.name, len(s.expectedUsers), copy.Users)
+		}
+		if role := copy.MemberRole(editorId + "|USER"); role != models.AppRoleEditor {
+			t.Errorf("[%s] Expected role %q, got %q", s.name, models.AppRoleEditor, role)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package forms
 
 import (
+	"encoding/json"
 	"strings"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
@@ -12,25 +13,36 @@ import (
 	"github.com/pedrozadotdev/pocketblocks/server/models"
 	"github.com/pedrozadotdev/pocketblocks/server/utils"
 	v "github.com/pocketbase/pocketbase/forms/validators"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/list"
 )
 
+// OnApplicationAfterSubmit hook is triggered after every successful
+// [ApplicationUpsert.Submit] call with the saved application.
+var OnApplicationAfterSubmit = &hook.Hook[*models.Application]{}
+
 // ApplicationUpsert is a [models.Application] upsert (create/update) form.
 type ApplicationUpsert struct {
 	dao         *daos.Dao
 	application *models.Application
 
-	Id       string   `form:"id" json:"id"`
-	Name     string   `form:"name" json:"name"`
-	Slug     string   `form:"slug" json:"slug"`
-	Type     int      `form:"type" json:"type"`
-	Status   string   `form:"status" json:"status"`
-	Public   bool     `form:"public" json:"public"`
-	AllUsers bool     `form:"allUsers" json:"allUsers"`
-	Groups   []string `form:"groups" json:"groups"`
-	Users    []string `form:"users" json:"users"`
-	AppDsl   string   `form:"appDSL" json:"appDSL"`
-	EditDsl  string   `form:"editDSL" json:"editDSL"`
-	FolderId string   `form:"folder" json:"folder"`
+	Id       string            `form:"id" json:"id"`
+	Name     string            `form:"name" json:"name"`
+	Slug     string            `form:"slug" json:"slug"`
+	Type     int               `form:"type" json:"type"`
+	Status   string            `form:"status" json:"status"`
+	Public   bool              `form:"public" json:"public"`
+	AllUsers bool              `form:"allUsers" json:"allUsers"`
+	Groups   []string          `form:"groups" json:"groups"`
+	Users    []string          `form:"users" json:"users"`
+	Roles    map[string]string `form:"roles" json:"roles"`
+	AppDsl   string            `form:"appDSL" json:"appDSL"`
+	EditDsl  string            `form:"editDSL" json:"editDSL"`
+	FolderId string            `form:"folder" json:"folder"`
+
+	IsTemplate bool   `form:"isTemplate" json:"isTemplate"`
+	TemplateId string `form:"templateId" json:"templateId"`
+	ReleaseId  string `form:"release" json:"release"`
 }
 
 // NewApplicationUpsert creates a new [ApplicationUpsert] form with initializer
@@ -55,9 +67,13 @@ func NewApplicationUpsert(dao *daos.Dao, application *models.Application) *Appli
 	form.AllUsers = application.AllUsers
 	form.Groups = application.Groups
 	form.Users = application.Users
+	form.Roles = application.Roles
 	form.AppDsl = application.AppDsl
 	form.EditDsl = application.EditDsl
 	form.FolderId = application.FolderId.String
+	form.IsTemplate = application.IsTemplate
+	form.TemplateId = application.TemplateId.String
+	form.ReleaseId = application.ReleaseId
 
 	return form
 }
@@ -98,6 +114,9 @@ func (form *ApplicationUpsert) Validate() error {
 		),
 			validation.By(validators.ValidMultiRelation(&form.dao.Dao, "users")),
 		),
+		validation.Field(&form.Roles, validation.Each(
+			validation.In(list.ToInterfaceSlice(models.AppRoles)...),
+		)),
 		validation.Field(&form.AppDsl, validation.Required, is.JSON),
 		validation.Field(&form.EditDsl, is.JSON),
 		validation.Field(&form.FolderId,
@@ -105,9 +124,38 @@ func (form *ApplicationUpsert) Validate() error {
 			validation.Match(utils.IdRegex),
 			validation.By(validators.ValidField(&form.dao.Dao, "_pbl_folders", "id")),
 		),
+		// the recorded template may have been deleted since, so only a changed one is checked
+		validation.Field(&form.TemplateId,
+			validation.When(
+				form.TemplateId != form.application.TemplateId.String,
+				validation.Length(utils.DefaultIdLength, utils.DefaultIdLength),
+				validation.Match(utils.IdRegex),
+				validation.By(validators.ValidField(&form.dao.Dao, "_pbl_apps", "id")),
+			),
+		),
+		validation.Field(&form.ReleaseId,
+			validation.When(
+				form.ReleaseId != form.application.ReleaseId,
+				validation.By(form.checkReleaseId),
+			),
+		),
 	)
 }
 
+func (form *ApplicationUpsert) checkReleaseId(value any) error {
+	v, _ := value.(string)
+	if v == "" {
+		return nil // nothing to check
+	}
+
+	release, err := form.dao.FindPblReleaseById(v)
+	if err != nil || release.AppId != form.application.Id {
+		return validation.NewError("validation_invalid_release", "The release is invalid or belongs to another application.")
+	}
+
+	return nil
+}
+
 // Submit validates the form and upserts the form application model.
 func (form *ApplicationUpsert) Submit() (*models.Application, error) {
 	if err := form.Validate(); err != nil {
@@ -136,6 +184,18 @@ func (form *ApplicationUpsert) Submit() (*models.Application, error) {
 	form.application.AllUsers = form.AllUsers
 	form.application.RawGroups = "[" + groupsStr + "]"
 	form.application.RawUsers = "[" + usersStr + "]"
+	form.application.Groups = list.ToUniqueStringSlice(form.application.RawGroups)
+	form.application.Users = list.ToUniqueStringSlice(form.application.RawUsers)
+
+	if form.Roles == nil {
+		form.Roles = map[string]string{}
+	}
+	rawRoles, err := json.Marshal(form.Roles)
+	if err != nil {
+		return nil, err
+	}
+	form.application.RawRoles = string(rawRoles)
+	form.application.Roles = form.Roles
 	form.application.AppDsl = form.AppDsl
 	form.application.EditDsl = form.EditDsl
 
@@ -145,6 +205,10 @@ func (form *ApplicationUpsert) Submit() (*models.Application, error) {
 		form.application.FolderId = null.NewString(form.FolderId, true)
 	}
 
+	form.application.IsTemplate = form.IsTemplate
+	form.application.TemplateId = null.NewString(form.TemplateId, form.TemplateId != "")
+	form.application.ReleaseId = form.ReleaseId
+
 	if form.Name != "" {
 		newSlug := slug.Make(form.Name)
 		err := validators.UniqueSlug(&form.dao.Dao, "_pbl_apps", form.Id)(newSlug)
@@ -159,5 +223,9 @@ func (form *ApplicationUpsert) Submit() (*models.Application, error) {
 		return nil, err
 	}
 
+	if err := OnApplicationAfterSubmit.Trigger(form.application); err != nil {
+		return nil, err
+	}
+
 	return form.application, nil
 }
@@ -0,0 +1,133 @@
+package forms
+
+import (
+	"encoding/json"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+	"github.com/pedrozadotdev/pocketblocks/server/daos"
+	"github.com/pedrozadotdev/pocketblocks/server/forms/validators"
+	"github.com/pedrozadotdev/pocketblocks/server/models"
+	"github.com/pedrozadotdev/pocketblocks/server/utils"
+	v "github.com/pocketbase/pocketbase/forms/validators"
+	"github.com/pocketbase/pocketbase/tools/list"
+)
+
+// DatasourceUpsert is a [models.Datasource] upsert (create/update) form.
+type DatasourceUpsert struct {
+	dao        *daos.Dao
+	datasource *models.Datasource
+
+	Id     string   `form:"id" json:"id"`
+	Name   string   `form:"name" json:"name"`
+	Type   string   `form:"type" json:"type"`
+	Config string   `form:"config" json:"config"`
+	Apps   []string `form:"apps" json:"apps"`
+}
+
+// NewDatasourceUpsert creates a new [DatasourceUpsert] form with initializer
+// config created from the provided [models.Datasource] instances
+// (for create you could pass a pointer to an empty Datasource - `&models.Datasource{}`).
+//
+// If you want to submit the form as part of a transaction,
+// you can change the default Dao via [SetDao()].
+func NewDatasourceUpsert(dao *daos.Dao, datasource *models.Datasource) *DatasourceUpsert {
+	form := &DatasourceUpsert{
+		dao:        dao,
+		datasource: datasource,
+	}
+
+	// load defaults
+	form.Id = datasource.Id
+	form.Name = datasource.Name
+	form.Type = datasource.Type
+	form.Config = datasource.Config
+	form.Apps = datasource.Apps
+
+	return form
+}
+
+// SetDao replaces the default form Dao instance with the provided one.
+func (form *DatasourceUpsert) SetDao(dao *daos.Dao) {
+	form.dao = dao
+}
+
+// Validate makes the form validatable by implementing [validation.Validatable] interface.
+func (form *DatasourceUpsert) Validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(
+			&form.Id,
+			validation.When(
+				form.datasource.IsNew(),
+				validation.Length(utils.DefaultIdLength, utils.DefaultIdLength),
+				validation.Match(utils.IdRegex),
+				validation.By(v.UniqueId(&form.dao.Dao, form.datasource.TableName())),
+			).Else(validation.In(form.datasource.Id)),
+		),
+		validation.Field(&form.Name, validation.Required, validation.Length(1, 100)),
+		validation.Field(
+			&form.Type,
+			validation.Required,
+			validation.In(list.ToInterfaceSlice(models.DatasourceTypes)...),
+		),
+		validation.Field(
+			&form.Config,
+			validation.Required,
+			is.JSON,
+			validation.By(form.checkConfig),
+		),
+		validation.Field(&form.Apps, validation.Each(
+			validation.Length(utils.DefaultIdLength, utils.DefaultIdLength),
+			validation.Match(utils.IdRegex),
+		),
+			validation.By(validators.ValidMultiRelation(&form.dao.Dao, "_pbl_apps")),
+		),
+	)
+}
+
+func (form *DatasourceUpsert) checkConfig(value any) error {
+	v, _ := value.(string)
+
+	config := models.NewDatasourceConfig(form.Type)
+	if config == nil {
+		return nil // invalid type error is reported by the type field
+	}
+
+	if err := json.Unmarshal([]byte(v), config); err != nil {
+		return validation.NewError("validation_invalid_config", "The config doesn't match the datasource type.")
+	}
+
+	return config.Validate()
+}
+
+// Submit validates the form and upserts the form datasource model.
+func (form *DatasourceUpsert) Submit() (*models.Datasource, error) {
+	if err := form.Validate(); err != nil {
+		return nil, err
+	}
+
+	// custom insertion id can be set only on create
+	if form.datasource.IsNew() && form.Id != "" {
+		form.datasource.MarkAsNew()
+		form.datasource.SetId(form.Id)
+	}
+
+	form.datasource.Id = form.Id
+	form.datasource.Name = form.Name
+	form.datasource.Type = form.Type
+	form.datasource.Config = form.Config
+
+	var appsStr string
+	if len(form.Apps) > 0 {
+		appsStr = "\"" + strings.Join(form.Apps, "\",\"") + "\""
+	}
+	form.datasource.RawApps = "[" + appsStr + "]"
+	form.datasource.Apps = form.Apps
+
+	if err := form.dao.SavePblDatasource(form.datasource); err != nil {
+		return nil, err
+	}
+
+	return form.datasource, nil
+}
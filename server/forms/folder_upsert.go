@@ -1,8 +1,12 @@
 package forms
 
 import (
+	"strings"
+
 	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/guregu/null"
 	"github.com/pedrozadotdev/pocketblocks/server/daos"
+	"github.com/pedrozadotdev/pocketblocks/server/forms/validators"
 	"github.com/pedrozadotdev/pocketblocks/server/models"
 	"github.com/pedrozadotdev/pocketblocks/server/utils"
 	v "github.com/pocketbase/pocketbase/forms/validators"
@@ -13,8 +17,12 @@ type FolderUpsert struct {
 	dao    *daos.Dao
 	folder *models.Folder
 
-	Id   string `form:"id" json:"id"`
-	Name string `form:"name" json:"name"`
+	Id       string   `form:"id" json:"id"`
+	Name     string   `form:"name" json:"name"`
+	ParentId string   `form:"parent" json:"parent"`
+	AllUsers bool     `form:"allUsers" json:"allUsers"`
+	Groups   []string `form:"groups" json:"groups"`
+	Users    []string `form:"users" json:"users"`
 }
 
 // NewFolderUpsert creates a new [FolderUpsert] form with initializer
@@ -32,6 +40,10 @@ func NewFolderUpsert(dao *daos.Dao, folder *models.Folder) *FolderUpsert {
 	// load defaults
 	form.Id = folder.Id
 	form.Name = folder.Name
+	form.ParentId = folder.ParentId.String
+	form.AllUsers = folder.AllUsers
+	form.Groups = folder.Groups
+	form.Users = folder.Users
 
 	return form
 }
@@ -57,9 +69,51 @@ func (form *FolderUpsert) Validate() error {
 			&form.Name,
 			validation.Required,
 		),
+		validation.Field(&form.ParentId,
+			validation.Length(utils.DefaultIdLength, utils.DefaultIdLength),
+			validation.Match(utils.IdRegex),
+			validation.By(validators.ValidField(&form.dao.Dao, "_pbl_folders", "id")),
+			validation.By(form.checkParentCycle),
+		),
+		validation.Field(&form.Groups, validation.Each(
+			validation.Length(utils.DefaultIdLength, utils.DefaultIdLength),
+			validation.Match(utils.IdRegex),
+		),
+			validation.By(validators.ValidMultiRelation(&form.dao.Dao, "groups")),
+		),
+		validation.Field(&form.Users, validation.Each(
+			validation.Length(utils.DefaultIdLength, utils.DefaultIdLength),
+			validation.Match(utils.IdRegex),
+		),
+			validation.By(validators.ValidMultiRelation(&form.dao.Dao, "users")),
+		),
 	)
 }
 
+// checkParentCycle ensures that the folder is not moved into itself or one of its subfolders.
+func (form *FolderUpsert) checkParentCycle(value any) error {
+	parentId, _ := value.(string)
+	if parentId == "" || form.folder.IsNew() {
+		return nil
+	}
+
+	visited := map[string]bool{}
+	for parentId != "" && !visited[parentId] {
+		if parentId == form.folder.Id {
+			return validation.NewError("validation_folder_cycle", "A folder cannot be moved into itself or one of its subfolders.")
+		}
+		visited[parentId] = true
+
+		parent, err := form.dao.FindPblFolderById(parentId)
+		if err != nil {
+			return nil // invalid parent error is reported by the ValidField rule
+		}
+		parentId = parent.ParentId.String
+	}
+
+	return nil
+}
+
 // Submit validates the form and upserts the form folder model.
 func (form *FolderUpsert) Submit() (*models.Folder, error) {
 	if err := form.Validate(); err != nil {
@@ -72,8 +126,27 @@ func (form *FolderUpsert) Submit() (*models.Folder, error) {
 		form.folder.SetId(form.Id)
 	}
 
+	var groupsStr, usersStr string
+	if len(form.Groups) > 0 {
+		groupsStr = "\"" + strings.Join(form.Groups, "\",\"") + "\""
+	}
+	if len(form.Users) > 0 {
+		usersStr = "\"" + strings.Join(form.Users, "\",\"") + "\""
+	}
+
 	form.folder.Id = form.Id
 	form.folder.Name = form.Name
+	form.folder.AllUsers = form.AllUsers
+	form.folder.RawGroups = "[" + groupsStr + "]"
+	form.folder.RawUsers = "[" + usersStr + "]"
+	form.folder.Groups = form.Groups
+	form.folder.Users = form.Users
+
+	if form.ParentId == "" {
+		form.folder.ParentId = null.NewString("", false)
+	} else {
+		form.folder.ParentId = null.NewString(form.ParentId, true)
+	}
 
 	if err := form.dao.SavePblFolder(form.folder); err != nil {
 		return nil, err
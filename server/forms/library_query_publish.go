@@ -0,0 +1,61 @@
+package forms
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pedrozadotdev/pocketblocks/server/daos"
+	"github.com/pedrozadotdev/pocketblocks/server/models"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// LibraryQueryPublish is a [models.LibraryQuery] publish form.
+//
+// On submit the library query draft becomes the version used by every application.
+type LibraryQueryPublish struct {
+	dao          *daos.Dao
+	libraryQuery *models.LibraryQuery
+
+	Tag           string `form:"tag" json:"tag"`
+	CommitMessage string `form:"commitMessage" json:"commitMessage"`
+}
+
+// NewLibraryQueryPublish creates a new [LibraryQueryPublish] form for the provided library query.
+//
+// If you want to submit the form as part of a transaction,
+// you can change the default Dao via [SetDao()].
+func NewLibraryQueryPublish(dao *daos.Dao, libraryQuery *models.LibraryQuery) *LibraryQueryPublish {
+	return &LibraryQueryPublish{
+		dao:          dao,
+		libraryQuery: libraryQuery,
+	}
+}
+
+// SetDao replaces the default form Dao instance with the provided one.
+func (form *LibraryQueryPublish) SetDao(dao *daos.Dao) {
+	form.dao = dao
+}
+
+// Validate makes the form validatable by implementing [validation.Validatable] interface.
+func (form *LibraryQueryPublish) Validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(&form.Tag, validation.Required, validation.Length(1, 50)),
+		validation.Field(&form.CommitMessage, validation.Length(0, 500)),
+	)
+}
+
+// Submit validates the form and publishes the library query draft.
+func (form *LibraryQueryPublish) Submit() (*models.LibraryQuery, error) {
+	if err := form.Validate(); err != nil {
+		return nil, err
+	}
+
+	form.libraryQuery.PublishedDsl = form.libraryQuery.EditDsl
+	form.libraryQuery.Tag = form.Tag
+	form.libraryQuery.CommitMessage = form.CommitMessage
+	form.libraryQuery.Published = types.NowDateTime()
+
+	if err := form.dao.SavePblLibraryQuery(form.libraryQuery); err != nil {
+		return nil, err
+	}
+
+	return form.libraryQuery, nil
+}
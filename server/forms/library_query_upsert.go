@@ -0,0 +1,91 @@
+package forms
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+	"github.com/pedrozadotdev/pocketblocks/server/daos"
+	"github.com/pedrozadotdev/pocketblocks/server/models"
+	"github.com/pedrozadotdev/pocketblocks/server/utils"
+	v "github.com/pocketbase/pocketbase/forms/validators"
+)
+
+// LibraryQueryUpsert is a [models.LibraryQuery] upsert (create/update) form.
+//
+// Only the draft version can be changed, use [LibraryQueryPublish] to publish it.
+type LibraryQueryUpsert struct {
+	dao          *daos.Dao
+	libraryQuery *models.LibraryQuery
+
+	Id      string `form:"id" json:"id"`
+	Name    string `form:"name" json:"name"`
+	EditDsl string `form:"editDSL" json:"editDSL"`
+}
+
+// NewLibraryQueryUpsert creates a new [LibraryQueryUpsert] form with initializer
+// config created from the provided [models.LibraryQuery] instances
+// (for create you could pass a pointer to an empty LibraryQuery - `&models.LibraryQuery{}`).
+//
+// If you want to submit the form as part of a transaction,
+// you can change the default Dao via [SetDao()].
+func NewLibraryQueryUpsert(dao *daos.Dao, libraryQuery *models.LibraryQuery) *LibraryQueryUpsert {
+	form := &LibraryQueryUpsert{
+		dao:          dao,
+		libraryQuery: libraryQuery,
+	}
+
+	// load defaults
+	form.Id = libraryQuery.Id
+	form.Name = libraryQuery.Name
+	form.EditDsl = libraryQuery.EditDsl
+
+	return form
+}
+
+// SetDao replaces the default form Dao instance with the provided one.
+func (form *LibraryQueryUpsert) SetDao(dao *daos.Dao) {
+	form.dao = dao
+}
+
+// Validate makes the form validatable by implementing [validation.Validatable] interface.
+func (form *LibraryQueryUpsert) Validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(
+			&form.Id,
+			validation.When(
+				form.libraryQuery.IsNew(),
+				validation.Length(utils.DefaultIdLength, utils.DefaultIdLength),
+				validation.Match(utils.IdRegex),
+				validation.By(v.UniqueId(&form.dao.Dao, form.libraryQuery.TableName())),
+			).Else(validation.In(form.libraryQuery.Id)),
+		),
+		validation.Field(&form.Name, validation.Required, validation.Length(1, 100)),
+		validation.Field(&form.EditDsl, validation.Required, is.JSON),
+	)
+}
+
+// Submit validates the form and upserts the form library query model.
+func (form *LibraryQueryUpsert) Submit() (*models.LibraryQuery, error) {
+	if err := form.Validate(); err != nil {
+		return nil, err
+	}
+
+	// custom insertion id can be set only on create
+	if form.libraryQuery.IsNew() && form.Id != "" {
+		form.libraryQuery.MarkAsNew()
+		form.libraryQuery.SetId(form.Id)
+	}
+
+	form.libraryQuery.Id = form.Id
+	form.libraryQuery.Name = form.Name
+	form.libraryQuery.EditDsl = form.EditDsl
+
+	if form.libraryQuery.PublishedDsl == "" {
+		form.libraryQuery.PublishedDsl = "{}"
+	}
+
+	if err := form.dao.SavePblLibraryQuery(form.libraryQuery); err != nil {
+		return nil, err
+	}
+
+	return form.libraryQuery, nil
+}
@@ -0,0 +1,76 @@
+package forms
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pedrozadotdev/pocketblocks/server/daos"
+	"github.com/pedrozadotdev/pocketblocks/server/models"
+	pbDaos "github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// ReleaseApply is a form that makes an approved release go live
+// by copying its dsl into the application AppDsl.
+type ReleaseApply struct {
+	dao     *daos.Dao
+	release *models.Release
+}
+
+// NewReleaseApply creates a new [ReleaseApply] form for the provided release.
+//
+// If you want to submit the form as part of a transaction,
+// you can change the default Dao via [SetDao()].
+func NewReleaseApply(dao *daos.Dao, release *models.Release) *ReleaseApply {
+	return &ReleaseApply{
+		dao:     dao,
+		release: release,
+	}
+}
+
+// SetDao replaces the default form Dao instance with the provided one.
+func (form *ReleaseApply) SetDao(dao *daos.Dao) {
+	form.dao = dao
+}
+
+// Validate makes the form validatable by implementing [validation.Validatable] interface.
+func (form *ReleaseApply) Validate() error {
+	if form.release.Status != models.ReleaseStatusApproved {
+		return validation.Errors{
+			"status": validation.NewError("validation_release_not_approved", "Only approved releases can be applied"),
+		}
+	}
+	return nil
+}
+
+// Submit validates the form, publishes the release dsl and returns the updated application.
+func (form *ReleaseApply) Submit() (*models.Application, error) {
+	if err := form.Validate(); err != nil {
+		return nil, err
+	}
+
+	var app *models.Application
+
+	txErr := form.dao.RunInTransaction(func(txDao *pbDaos.Dao) error {
+		dao := daos.New(txDao.DB())
+
+		current, err := dao.FindPblAppById(form.release.AppId)
+		if err != nil {
+			return err
+		}
+
+		appForm := NewApplicationUpsert(dao, current)
+		appForm.AppDsl = form.release.Dsl
+		appForm.ReleaseId = form.release.Id
+		if app, err = appForm.Submit(); err != nil {
+			return err
+		}
+
+		form.release.Status = models.ReleaseStatusApplied
+		form.release.AppliedAt = types.NowDateTime()
+		return dao.SavePblRelease(form.release)
+	})
+	if txErr != nil {
+		return nil, txErr
+	}
+
+	return app, nil
+}
@@ -0,0 +1,147 @@
+package forms
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pedrozadotdev/pocketblocks/server/daos"
+	"github.com/pedrozadotdev/pocketblocks/server/models"
+	"github.com/pedrozadotdev/pocketblocks/server/utils"
+	"github.com/pocketbase/dbx"
+	pbDaos "github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// ReleaseRequest is a form that requests publishing the current application
+// edit dsl as a new pending release (see [ReleaseReview]).
+type ReleaseRequest struct {
+	dao         *daos.Dao
+	application *models.Application
+	requestedBy string
+
+	// Version is the release semantic version, which must be greater than
+	// the previous releases one (default to the next patch version).
+	Version string `form:"version" json:"version"`
+	Notes   string `form:"notes" json:"notes"`
+
+	// ScheduledAt is the optional time the release goes live at once approved.
+	ScheduledAt types.DateTime `form:"scheduledAt" json:"scheduledAt"`
+}
+
+// NewReleaseRequest creates a new [ReleaseRequest] form for the provided
+// application on behalf of the provided user/admin id.
+//
+// If you want to submit the form as part of a transaction,
+// you can change the default Dao via [SetDao()].
+func NewReleaseRequest(dao *daos.Dao, application *models.Application, requestedBy string) *ReleaseRequest {
+	return &ReleaseRequest{
+		dao:         dao,
+		application: application,
+		requestedBy: requestedBy,
+	}
+}
+
+// SetDao replaces the default form Dao instance with the provided one.
+func (form *ReleaseRequest) SetDao(dao *daos.Dao) {
+	form.dao = dao
+}
+
+// Validate makes the form validatable by implementing [validation.Validatable] interface.
+func (form *ReleaseRequest) Validate() error {
+	if err := validation.Validate(form.application.EditDsl, validation.Required); err != nil {
+		return validation.Errors{"editDSL": err}
+	}
+
+	return validation.ValidateStruct(form,
+		validation.Field(&form.Version,
+			validation.Match(utils.SemverRegex),
+			validation.By(form.checkVersion),
+		),
+		validation.Field(&form.Notes, validation.Length(0, 10000)),
+	)
+}
+
+func (form *ReleaseRequest) checkVersion(value any) error {
+	v, _ := value.(string)
+	version, err := utils.ParseSemver(v)
+	if err != nil {
+		return nil // empty or already checked by the regex
+	}
+
+	latest, err := form.latestVersion()
+	if err != nil {
+		return err
+	}
+	if latest != nil && version.Compare(latest) <= 0 {
+		return validation.NewError("validation_version_not_greater", "The version must be greater than "+latest.String())
+	}
+
+	return nil
+}
+
+// latestVersion returns the highest version of the app (non rejected) releases.
+func (form *ReleaseRequest) latestVersion() (*utils.Semver, error) {
+	versions := []string{}
+	if err := form.dao.PblReleaseQuery().
+		Select("version").
+		AndWhere(dbx.HashExp{"app": form.application.Id}).
+		AndWhere(dbx.Not(dbx.HashExp{"status": models.ReleaseStatusRejected})).
+		Column(&versions); err != nil {
+		return nil, err
+	}
+
+	var latest *utils.Semver
+	for _, v := range versions {
+		if version, err := utils.ParseSemver(v); err == nil && (latest == nil || version.Compare(latest) > 0) {
+			latest = version
+		}
+	}
+
+	return latest, nil
+}
+
+// Submit validates the form and creates the pending release.
+//
+// The version check and the release insert run in the same transaction,
+// so concurrent requests can't create the same version.
+func (form *ReleaseRequest) Submit() (*models.Release, error) {
+	var release *models.Release
+
+	txErr := form.dao.RunInTransaction(func(txDao *pbDaos.Dao) error {
+		// the same form checking the latest version within the transaction
+		txForm := *form
+		txForm.SetDao(daos.New(txDao.DB()))
+
+		if err := txForm.Validate(); err != nil {
+			return err
+		}
+
+		version := form.Version
+		if version == "" {
+			latest, err := txForm.latestVersion()
+			if err != nil {
+				return err
+			}
+
+			version = "1.0.0"
+			if latest != nil {
+				version = latest.NextPatch().String()
+			}
+		}
+
+		release = &models.Release{
+			AppId:       form.application.Id,
+			Dsl:         form.application.EditDsl,
+			Version:     version,
+			Notes:       form.Notes,
+			Status:      models.ReleaseStatusPending,
+			RequestedBy: form.requestedBy,
+			ScheduledAt: form.ScheduledAt,
+		}
+
+		return txForm.dao.SavePblRelease(release)
+	})
+	if txErr != nil {
+		return nil, txErr
+	}
+
+	return release, nil
+}
@@ -0,0 +1,83 @@
+package forms
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pedrozadotdev/pocketblocks/server/daos"
+	"github.com/pedrozadotdev/pocketblocks/server/models"
+	pbDaos "github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// ReleaseReview is a form that approves or rejects a pending release.
+//
+// An approved release goes live right away, unless it is scheduled
+// for later (see [daos.Dao.FindPblDueReleases]).
+type ReleaseReview struct {
+	dao        *daos.Dao
+	release    *models.Release
+	reviewedBy string
+
+	Approve bool `form:"approve" json:"approve"`
+}
+
+// NewReleaseReview creates a new [ReleaseReview] form for the provided
+// release on behalf of the provided user/admin id.
+//
+// If you want to submit the form as part of a transaction,
+// you can change the default Dao via [SetDao()].
+func NewReleaseReview(dao *daos.Dao, release *models.Release, reviewedBy string) *ReleaseReview {
+	return &ReleaseReview{
+		dao:        dao,
+		release:    release,
+		reviewedBy: reviewedBy,
+	}
+}
+
+// SetDao replaces the default form Dao instance with the provided one.
+func (form *ReleaseReview) SetDao(dao *daos.Dao) {
+	form.dao = dao
+}
+
+// Validate makes the form validatable by implementing [validation.Validatable] interface.
+func (form *ReleaseReview) Validate() error {
+	if form.release.Status != models.ReleaseStatusPending {
+		return validation.Errors{
+			"status": validation.NewError("validation_release_not_pending", "Only pending releases can be reviewed"),
+		}
+	}
+	return nil
+}
+
+// Submit validates the form and saves the review (applying the release if due).
+func (form *ReleaseReview) Submit() (*models.Release, error) {
+	if err := form.Validate(); err != nil {
+		return nil, err
+	}
+
+	txErr := form.dao.RunInTransaction(func(txDao *pbDaos.Dao) error {
+		dao := daos.New(txDao.DB())
+
+		form.release.ReviewedBy = form.reviewedBy
+		if !form.Approve {
+			form.release.Status = models.ReleaseStatusRejected
+			return dao.SavePblRelease(form.release)
+		}
+
+		form.release.Status = models.ReleaseStatusApproved
+		if err := dao.SavePblRelease(form.release); err != nil {
+			return err
+		}
+
+		if !form.release.IsDue(types.NowDateTime()) {
+			return nil
+		}
+
+		_, err := NewReleaseApply(dao, form.release).Submit()
+		return err
+	})
+	if txErr != nil {
+		return nil, txErr
+	}
+
+	return form.release, nil
+}
@@ -0,0 +1,61 @@
+package forms
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pedrozadotdev/pocketblocks/server/daos"
+	"github.com/pedrozadotdev/pocketblocks/server/models"
+)
+
+// ReleaseRollback is a form that makes a previously applied release
+// live again (its dsl replaces the application AppDsl, the EditDsl is left untouched).
+type ReleaseRollback struct {
+	dao         *daos.Dao
+	application *models.Application
+	release     *models.Release
+}
+
+// NewReleaseRollback creates a new [ReleaseRollback] form for the provided
+// application and release.
+//
+// If you want to submit the form as part of a transaction,
+// you can change the default Dao via [SetDao()].
+func NewReleaseRollback(dao *daos.Dao, application *models.Application, release *models.Release) *ReleaseRollback {
+	return &ReleaseRollback{
+		dao:         dao,
+		application: application,
+		release:     release,
+	}
+}
+
+// SetDao replaces the default form Dao instance with the provided one.
+func (form *ReleaseRollback) SetDao(dao *daos.Dao) {
+	form.dao = dao
+}
+
+// Validate makes the form validatable by implementing [validation.Validatable] interface.
+func (form *ReleaseRollback) Validate() error {
+	if form.release.AppId != form.application.Id {
+		return validation.Errors{
+			"release": validation.NewError("validation_invalid_release", "The release belongs to another application"),
+		}
+	}
+	if form.release.Status != models.ReleaseStatusApplied {
+		return validation.Errors{
+			"status": validation.NewError("validation_release_not_applied", "Only previously applied releases can be rolled back to"),
+		}
+	}
+	return nil
+}
+
+// Submit validates the form and returns the application with the release dsl live.
+func (form *ReleaseRollback) Submit() (*models.Application, error) {
+	if err := form.Validate(); err != nil {
+		return nil, err
+	}
+
+	appForm := NewApplicationUpsert(form.dao, form.application)
+	appForm.AppDsl = form.release.Dsl
+	appForm.ReleaseId = form.release.Id
+
+	return appForm.Submit()
+}
@@ -0,0 +1,93 @@
+package forms
+
+import (
+	"encoding/json"
+
+	"github.com/pedrozadotdev/pocketblocks/server/daos"
+	"github.com/pedrozadotdev/pocketblocks/server/models"
+	pbDaos "github.com/pocketbase/pocketbase/daos"
+)
+
+// SnapshotRestore is a form that rolls an application back to one of its snapshots.
+//
+// On submit the current application state is saved as a new snapshot first,
+// so the restore itself can be undone.
+type SnapshotRestore struct {
+	dao         *daos.Dao
+	application *models.Application
+	snapshot    *models.Snapshot
+	requestedBy string
+
+	// RestorePublished also requests publishing the restored DSL as a new
+	// pending release (see [ReleaseRequest]), instead of writing AppDsl directly.
+	RestorePublished bool `form:"restorePublished" json:"restorePublished"`
+}
+
+// NewSnapshotRestore creates a new [SnapshotRestore] form for the provided
+// application and snapshot on behalf of the provided user/admin id.
+//
+// If you want to submit the form as part of a transaction,
+// you can change the default Dao via [SetDao()].
+func NewSnapshotRestore(dao *daos.Dao, application *models.Application, snapshot *models.Snapshot, requestedBy string) *SnapshotRestore {
+	return &SnapshotRestore{
+		dao:         dao,
+		application: application,
+		snapshot:    snapshot,
+		requestedBy: requestedBy,
+	}
+}
+
+// SetDao replaces the default form Dao instance with the provided one.
+func (form *SnapshotRestore) SetDao(dao *daos.Dao) {
+	form.dao = dao
+}
+
+// Submit restores the snapshot and returns the updated application,
+// the snapshot holding its previous state and, if RestorePublished is set,
+// the pending release of the restored DSL.
+func (form *SnapshotRestore) Submit() (*models.Application, *models.Snapshot, *models.Release, error) {
+	var backup *models.Snapshot
+	var release *models.Release
+
+	// same context format used by the editor history when recovering a version
+	context, err := json.Marshal(map[string]any{
+		"operations": []any{map[string]any{
+			"compName":           "rootComp",
+			"operation":          "recover",
+			"snapshotCreateTime": form.snapshot.Created.Time().UnixMilli(),
+		}},
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	txErr := form.dao.RunInTransaction(func(txDao *pbDaos.Dao) error {
+		dao := daos.New(txDao.DB())
+
+		backupForm := NewSnapshotUpsert(dao, &models.Snapshot{})
+		backupForm.AppId = form.application.Id
+		backupForm.Dsl = form.application.EditDsl
+		backupForm.Context = string(context)
+
+		var err error
+		if backup, err = backupForm.Submit(); err != nil {
+			return err
+		}
+
+		appForm := NewApplicationUpsert(dao, form.application)
+		appForm.EditDsl = form.snapshot.Dsl
+		if _, err := appForm.Submit(); err != nil {
+			return err
+		}
+
+		if form.RestorePublished {
+			release, err = NewReleaseRequest(dao, form.application, form.requestedBy).Submit()
+		}
+		return err
+	})
+	if txErr != nil {
+		return nil, nil, nil, txErr
+	}
+
+	return form.application, backup, release, nil
+}
@@ -0,0 +1,120 @@
+package gitsync
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/pedrozadotdev/pocketblocks/server/daos"
+	"github.com/pedrozadotdev/pocketblocks/server/forms"
+	"github.com/pedrozadotdev/pocketblocks/server/models"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/spf13/cobra"
+)
+
+// Config defines the config options of the git sync.
+type Config struct {
+	// Dir is the git working directory where the apps are mirrored
+	// (the sync is disabled if empty).
+	Dir string
+
+	// Remote is an optional git remote url or path (eg. a local bare repo)
+	// the mirrored changes are pushed to and imported from.
+	Remote string
+}
+
+// MustRegister registers the "gitsync export|import" commands and the
+// apps mirroring hook to the provided app instance and panic if it fails.
+//
+// Example usage:
+//
+//	gitsync.MustRegister(app, app.RootCmd, gitsync.Config{Dir: "./pbl_git"})
+func MustRegister(app core.App, rootCmd *cobra.Command, config Config) {
+	if err := Register(app, rootCmd, config); err != nil {
+		panic(err)
+	}
+}
+
+// Register registers the "gitsync export|import" commands and the
+// apps mirroring hook to the provided app instance.
+func Register(app core.App, rootCmd *cobra.Command, config Config) error {
+	repo := New(config)
+
+	if config.Dir != "" {
+		queue := newMirrorQueue(repo, func() *daos.Dao {
+			return daos.New(app.Dao().DB())
+		}, func(appId string, err error) {
+			app.Logger().Error("Failed to mirror the app into the git repository", "app", appId, "error", err.Error())
+		})
+
+		// the mirror runs in the background once the app save has committed
+		// (a failed mirror shouldn't prevent saving the app either)
+		forms.OnApplicationAfterSubmit.Add(func(application *models.Application) error {
+			queue.add(application.Id)
+			return nil
+		})
+
+		app.OnTerminate().Add(func(e *core.TerminateEvent) error {
+			queue.flush()
+			return nil
+		})
+	}
+
+	command := &cobra.Command{
+		Use:   "gitsync",
+		Short: "Syncs the PocketBlocks applications with a git repository",
+	}
+
+	command.AddCommand(exportCmd(app, repo), importCmd(app, repo))
+	rootCmd.AddCommand(command)
+
+	return nil
+}
+
+var errMissingDir = errors.New("the --gitSyncDir flag is required")
+
+func exportCmd(app core.App, repo *Repo) *cobra.Command {
+	return &cobra.Command{
+		Use:          "export",
+		Short:        "Mirrors all applications into the git repository",
+		SilenceUsage: true,
+		RunE: func(command *cobra.Command, args []string) error {
+			if repo.config.Dir == "" {
+				return errMissingDir
+			}
+
+			total, err := repo.MirrorAll(daos.New(app.Dao().DB()))
+			if err != nil {
+				return err
+			}
+
+			color.Green("Successfully exported %d application(s) to %s", total, repo.config.Dir)
+			return nil
+		},
+	}
+}
+
+func importCmd(app core.App, repo *Repo) *cobra.Command {
+	return &cobra.Command{
+		Use:          "import",
+		Short:        "Imports the applications changes from the git repository",
+		SilenceUsage: true,
+		RunE: func(command *cobra.Command, args []string) error {
+			if repo.config.Dir == "" {
+				return errMissingDir
+			}
+
+			result, err := repo.Import(daos.New(app.Dao().DB()))
+			if err != nil {
+				return err
+			}
+
+			color.Green("Successfully imported the applications from %s", repo.config.Dir)
+			fmt.Printf("Created: %s\n", strings.Join(result.Created, ", "))
+			fmt.Printf("Updated: %s\n", strings.Join(result.Updated, ", "))
+			fmt.Printf("Unchanged: %d\n", len(result.Unchanged))
+			return nil
+		},
+	}
+}
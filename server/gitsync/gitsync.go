@@ -0,0 +1,440 @@
+// Package gitsync mirrors the PocketBlocks applications into a local git
+// repository (one pretty-printed JSON file per app slug) and imports
+// the changes made in that repository back into the database.
+//
+// The repository can optionally track a remote (eg. a local bare repo),
+// which is pulled before and pushed after every change.
+//
+// Example usage:
+//
+//	gitsync.MustRegister(app, app.RootCmd, gitsync.Config{Dir: "./pbl_git"})
+package gitsync
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/pedrozadotdev/pocketblocks/server/daos"
+	"github.com/pedrozadotdev/pocketblocks/server/forms"
+	"github.com/pedrozadotdev/pocketblocks/server/models"
+	"github.com/pedrozadotdev/pocketblocks/server/utils"
+	pbDaos "github.com/pocketbase/pocketbase/daos"
+)
+
+// Branch is the synced git branch.
+const Branch = "main"
+
+// AppsDir is the repository directory with the application files.
+const AppsDir = "apps"
+
+// AppFile is the content of a mirrored application file.
+type AppFile struct {
+	Id      string          `json:"id"`
+	Name    string          `json:"name"`
+	Type    int             `json:"type"`
+	Status  string          `json:"status"`
+	EditDsl json.RawMessage `json:"editDSL"`
+	AppDsl  json.RawMessage `json:"appDSL"`
+}
+
+// ImportResult lists the application slugs affected by an import.
+type ImportResult struct {
+	Created   []string `json:"created"`
+	Updated   []string `json:"updated"`
+	Unchanged []string `json:"unchanged"`
+}
+
+// Repo is a git repository that mirrors the PocketBlocks applications.
+type Repo struct {
+	config    Config
+	mux       sync.Mutex
+	importing atomic.Bool
+}
+
+// New creates a new Repo instance from the provided config.
+func New(config Config) *Repo {
+	return &Repo{config: config}
+}
+
+// Mirror writes the application file into the repository
+// and commits (and pushes) it if it has changed.
+func (r *Repo) Mirror(app *models.Application) error {
+	// the imported apps are committed all together at the end of the import
+	if r.importing.Load() {
+		return nil
+	}
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	if err := r.init(); err != nil {
+		return err
+	}
+
+	if err := r.writeApp(app); err != nil {
+		return err
+	}
+
+	return r.commit("Update " + app.Slug)
+}
+
+// MirrorAll writes the files of all applications into the repository
+// and commits (and pushes) the changes in a single commit.
+func (r *Repo) MirrorAll(dao *daos.Dao) (int, error) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	if err := r.init(); err != nil {
+		return 0, err
+	}
+
+	apps := []*models.Application{}
+	if err := dao.PblAppQuery().OrderBy("created ASC").All(&apps); err != nil {
+		return 0, err
+	}
+
+	for _, app := range apps {
+		if err := r.writeApp(app); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(apps), r.commit("Export all applications")
+}
+
+// Import pulls the remote changes (if any) and upserts the applications
+// from the repository files into the database.
+//
+// The apps are matched by id first and then by slug (the file name).
+// The apps without a file are left untouched.
+func (r *Repo) Import(dao *daos.Dao) (*ImportResult, error) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	if err := r.init(); err != nil {
+		return nil, err
+	}
+
+	files, err := r.readApps()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{
+		Created:   []string{},
+		Updated:   []string{},
+		Unchanged: []string{},
+	}
+	saved := map[string]*models.Application{}
+
+	r.importing.Store(true)
+	txErr := dao.RunInTransaction(func(txDao *pbDaos.Dao) error {
+		dao := daos.New(txDao.DB())
+
+		for _, slug := range slices.Sorted(maps.Keys(files)) {
+			app, created, changed, err := importApp(dao, slug, files[slug])
+			if err != nil {
+				return fmt.Errorf("failed to import %s: %w", slug, err)
+			}
+
+			switch {
+			case created:
+				result.Created = append(result.Created, app.Slug)
+			case changed:
+				result.Updated = append(result.Updated, app.Slug)
+			default:
+				result.Unchanged = append(result.Unchanged, app.Slug)
+			}
+
+			if created || changed {
+				saved[slug] = app
+			}
+		}
+
+		return nil
+	})
+	r.importing.Store(false)
+	if txErr != nil {
+		return nil, txErr
+	}
+
+	// sync back the files of the saved apps (eg. a new app slug)
+	for _, slug := range slices.Sorted(maps.Keys(saved)) {
+		if err := r.writeApp(saved[slug]); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := r.commit("Import applications"); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func importApp(dao *daos.Dao, slug string, file *AppFile) (app *models.Application, created bool, changed bool, err error) {
+	if file.Id != "" {
+		app, _ = dao.FindPblAppById(file.Id)
+	}
+	if app == nil {
+		app, _ = dao.FindPblAppBySlug(slug, nil)
+	}
+
+	editDsl, err := compactDsl(file.EditDsl)
+	if err != nil {
+		return nil, false, false, err
+	}
+	appDsl, err := compactDsl(file.AppDsl)
+	if err != nil {
+		return nil, false, false, err
+	}
+
+	if app == nil {
+		app = &models.Application{}
+		created = true
+	} else {
+		currentEditDsl, _ := compactDsl(json.RawMessage(app.EditDsl))
+		currentAppDsl, _ := compactDsl(json.RawMessage(app.AppDsl))
+
+		if currentEditDsl == editDsl &&
+			(appDsl == "" || currentAppDsl == appDsl) &&
+			(file.Name == "" || file.Name == app.Name) &&
+			(file.Status == "" || file.Status == app.Status) {
+			return app, false, false, nil
+		}
+		changed = true
+	}
+
+	form := forms.NewApplicationUpsert(dao, app)
+	if created {
+		// keep the file id (if valid) so the next imports match the same app
+		if len(file.Id) == utils.DefaultIdLength && utils.IdRegex.MatchString(file.Id) {
+			form.Id = file.Id
+		}
+		form.Name = slug
+		form.Type = 1
+		form.Status = "NORMAL"
+		form.AppDsl = "{}"
+	}
+	if file.Name != "" {
+		form.Name = file.Name
+	}
+	if file.Type > 0 {
+		form.Type = file.Type
+	}
+	if file.Status != "" {
+		form.Status = file.Status
+	}
+	if appDsl != "" {
+		form.AppDsl = appDsl
+	}
+	form.EditDsl = editDsl
+
+	app, err = form.Submit()
+	if err != nil {
+		return nil, false, false, err
+	}
+
+	return app, created, changed, nil
+}
+
+func compactDsl(raw json.RawMessage) (string, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", nil
+	}
+
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, raw); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// -------------------------------------------------------------------
+// Files
+// -------------------------------------------------------------------
+
+func (r *Repo) appsDir() string {
+	return filepath.Join(r.config.Dir, AppsDir)
+}
+
+// readApps returns the repository app files indexed by slug.
+func (r *Repo) readApps() (map[string]*AppFile, error) {
+	paths, err := filepath.Glob(filepath.Join(r.appsDir(), "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]*AppFile, len(paths))
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+
+		file := &AppFile{}
+		if err := json.Unmarshal(data, file); err != nil {
+			return nil, fmt.Errorf("invalid app file %s: %w", filepath.Base(p), err)
+		}
+
+		files[strings.TrimSuffix(filepath.Base(p), ".json")] = file
+	}
+
+	return files, nil
+}
+
+// writeApp writes the app file, removing the files of the same app
+// with an old slug.
+func (r *Repo) writeApp(app *models.Application) error {
+	files, err := r.readApps()
+	if err != nil {
+		return err
+	}
+	for slug, file := range files {
+		if slug != app.Slug && file.Id == app.Id {
+			if err := os.Remove(filepath.Join(r.appsDir(), slug+".json")); err != nil {
+				return err
+			}
+		}
+	}
+
+	file := &AppFile{
+		Id:      app.Id,
+		Name:    app.Name,
+		Type:    app.Type,
+		Status:  app.Status,
+		EditDsl: rawDsl(app.EditDsl),
+		AppDsl:  rawDsl(app.AppDsl),
+	}
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(r.appsDir(), os.ModePerm); err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(r.appsDir(), app.Slug+".json"), append(data, '\n'), 0644)
+}
+
+func rawDsl(dsl string) json.RawMessage {
+	if strings.TrimSpace(dsl) == "" {
+		return json.RawMessage("null")
+	}
+	return json.RawMessage(dsl)
+}
+
+// -------------------------------------------------------------------
+// Git
+// -------------------------------------------------------------------
+
+func (r *Repo) git(args ...string) (string, error) {
+	var stderr bytes.Buffer
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.config.Dir
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// init creates the repository (if missing), sets its remote
+// and pulls the remote changes.
+func (r *Repo) init() error {
+	if r.config.Dir == "" {
+		return errors.New("the git sync dir is not configured")
+	}
+
+	if err := os.MkdirAll(r.config.Dir, os.ModePerm); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(filepath.Join(r.config.Dir, ".git")); os.IsNotExist(err) {
+		if _, err := r.git("init", "-q"); err != nil {
+			return err
+		}
+		if _, err := r.git("symbolic-ref", "HEAD", "refs/heads/"+Branch); err != nil {
+			return err
+		}
+	}
+
+	if r.config.Remote == "" {
+		return nil
+	}
+
+	url, err := r.git("remote", "get-url", "origin")
+	if err != nil {
+		_, err = r.git("remote", "add", "origin", r.config.Remote)
+	} else if url != r.config.Remote {
+		_, err = r.git("remote", "set-url", "origin", r.config.Remote)
+	}
+	if err != nil {
+		return err
+	}
+
+	return r.pull()
+}
+
+func (r *Repo) pull() error {
+	heads, err := r.git("ls-remote", "--heads", "origin", Branch)
+	if err != nil || heads == "" {
+		// empty remote
+		return err
+	}
+
+	if _, err := r.git("fetch", "-q", "origin", Branch); err != nil {
+		return err
+	}
+
+	_, err = r.git("merge", "-q", "--ff-only", "FETCH_HEAD")
+	return err
+}
+
+// commit commits the apps dir changes (if any) and pushes them to the remote.
+func (r *Repo) commit(message string) error {
+	if _, err := r.git("add", "-A", "--", AppsDir); err != nil {
+		return err
+	}
+
+	status, err := r.git("status", "--porcelain", "--", AppsDir)
+	if err != nil {
+		return err
+	}
+	if status == "" {
+		return nil
+	}
+
+	args := []string{"commit", "-q", "-m", message}
+	if email, _ := r.git("config", "user.email"); email == "" {
+		args = append([]string{"-c", "user.name=PocketBlocks", "-c", "user.email=pocketblocks@localhost"}, args...)
+	}
+	if _, err := r.git(args...); err != nil {
+		return err
+	}
+
+	if r.config.Remote == "" {
+		return nil
+	}
+
+	_, err = r.git("push", "-q", "origin", "HEAD:refs/heads/"+Branch)
+	return err
+}
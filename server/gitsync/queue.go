@@ -0,0 +1,77 @@
+package gitsync
+
+import (
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/pedrozadotdev/pocketblocks/server/daos"
+)
+
+// MirrorDelay is how long the queued apps wait before being mirrored,
+// so the transaction that saved them has committed and bursts of saves
+// (eg. editor autosaves) end up in a single mirror.
+var MirrorDelay = 2 * time.Second
+
+// mirrorQueue mirrors the saved applications in the background.
+//
+// Only the app ids are queued and the apps are reloaded from the database
+// when mirrored, so the state of a rolled back transaction is never
+// committed into the repository.
+type mirrorQueue struct {
+	repo    *Repo
+	dao     func() *daos.Dao
+	onError func(appId string, err error)
+
+	mux     sync.Mutex
+	pending map[string]struct{}
+	timer   *time.Timer
+}
+
+func newMirrorQueue(repo *Repo, dao func() *daos.Dao, onError func(appId string, err error)) *mirrorQueue {
+	return &mirrorQueue{
+		repo:    repo,
+		dao:     dao,
+		onError: onError,
+		pending: map[string]struct{}{},
+	}
+}
+
+// add queues the mirroring of the provided app, postponing the
+// pending mirrors by [MirrorDelay].
+func (q *mirrorQueue) add(appId string) {
+	q.mux.Lock()
+	defer q.mux.Unlock()
+
+	q.pending[appId] = struct{}{}
+
+	if q.timer != nil {
+		q.timer.Stop()
+	}
+	q.timer = time.AfterFunc(MirrorDelay, q.flush)
+}
+
+// flush mirrors the pending apps right away.
+func (q *mirrorQueue) flush() {
+	q.mux.Lock()
+	if q.timer != nil {
+		q.timer.Stop()
+		q.timer = nil
+	}
+	appIds := slices.Sorted(maps.Keys(q.pending))
+	q.pending = map[string]struct{}{}
+	q.mux.Unlock()
+
+	dao := q.dao()
+	for _, appId := range appIds {
+		app, err := dao.FindPblAppById(appId)
+		if err != nil {
+			continue // not committed (or deleted in the meantime)
+		}
+
+		if err := q.repo.Mirror(app); err != nil {
+			q.onError(appId, err)
+		}
+	}
+}
@@ -0,0 +1,456 @@
+// Package jsonpatch implements the RFC 6902 JSON patches
+// (add, remove, replace, move, copy and test operations).
+//
+// Example usage:
+//
+//	patch, err := jsonpatch.Decode([]byte(`[{"op": "remove", "path": "/ui/comp/button1"}]`))
+//	if err != nil {
+//		return err
+//	}
+//	result, err := patch.Apply(doc)
+package jsonpatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	OpAdd     = "add"
+	OpRemove  = "remove"
+	OpReplace = "replace"
+	OpMove    = "move"
+	OpCopy    = "copy"
+	OpTest    = "test"
+)
+
+// Operation is a single patch operation.
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// Modified returns the pointers of the locations changed by the operation
+// (the "from" of a copy and the "test" operations are read-only).
+func (o *Operation) Modified() []string {
+	switch o.Op {
+	case OpTest:
+		return nil
+	case OpMove:
+		return []string{o.From, o.Path}
+	default:
+		return []string{o.Path}
+	}
+}
+
+// Patch is a list of operations applied in order.
+type Patch []*Operation
+
+// OpError is the error of a failed patch operation.
+type OpError struct {
+	Index int
+	Err   error
+}
+
+// Error implements the [error] interface.
+func (e *OpError) Error() string {
+	return fmt.Sprintf("operation %d: %v", e.Index, e.Err)
+}
+
+// Unwrap returns the underlying operation error.
+func (e *OpError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	ErrInvalidOp      = errors.New("invalid operation")
+	ErrInvalidPointer = errors.New("invalid JSON pointer")
+	ErrMissingValue   = errors.New("the value is missing")
+	ErrNotFound       = errors.New("the path doesn't exist")
+	ErrTestFailed     = errors.New("the test operation failed")
+)
+
+// Decode parses and checks the raw patch operations list.
+func Decode(raw []byte) (Patch, error) {
+	patch := Patch{}
+	if err := json.Unmarshal(raw, &patch); err != nil {
+		return nil, err
+	}
+
+	for i, o := range patch {
+		if o == nil {
+			return nil, &OpError{Index: i, Err: ErrInvalidOp}
+		}
+		if err := o.check(); err != nil {
+			return nil, &OpError{Index: i, Err: err}
+		}
+	}
+
+	return patch, nil
+}
+
+func (o *Operation) check() error {
+	switch o.Op {
+	case OpAdd, OpReplace, OpTest:
+		if o.Value == nil {
+			return ErrMissingValue
+		}
+	case OpMove, OpCopy:
+		if _, err := ParsePointer(o.From); err != nil {
+			return fmt.Errorf("from: %w", err)
+		}
+	case OpRemove:
+	default:
+		return fmt.Errorf("%w %q", ErrInvalidOp, o.Op)
+	}
+
+	if _, err := ParsePointer(o.Path); err != nil {
+		return fmt.Errorf("path: %w", err)
+	}
+
+	return nil
+}
+
+// Apply applies the patch to the raw JSON document and returns the patched one.
+//
+// The document is left unchanged if any operation fails.
+func (p Patch) Apply(doc []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	dec.UseNumber()
+
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	for i, o := range p {
+		var err error
+		if value, err = o.apply(value); err != nil {
+			return nil, &OpError{Index: i, Err: err}
+		}
+	}
+
+	return json.Marshal(value)
+}
+
+func (o *Operation) apply(doc any) (any, error) {
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+
+	path, _ := ParsePointer(o.Path)
+
+	switch o.Op {
+	case OpAdd:
+		value, err := decodeValue(o.Value)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, path, value)
+	case OpRemove:
+		doc, _, err := remove(doc, path)
+		return doc, err
+	case OpReplace:
+		value, err := decodeValue(o.Value)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := get(doc, path); err != nil {
+			return nil, err
+		}
+		if len(path) == 0 {
+			return value, nil
+		}
+		return update(doc, path, func(container any, key string) (any, error) {
+			switch c := container.(type) {
+			case map[string]any:
+				c[key] = value
+			case []any:
+				i, _ := index(key, len(c))
+				c[i] = value
+			}
+			return container, nil
+		})
+	case OpMove:
+		from, _ := ParsePointer(o.From)
+		if o.Path == o.From {
+			_, err := get(doc, from)
+			return doc, err
+		}
+		if strings.HasPrefix(o.Path, o.From+"/") {
+			return nil, fmt.Errorf("%w: a location can't be moved into one of its children", ErrInvalidOp)
+		}
+		doc, value, err := remove(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, path, value)
+	case OpCopy:
+		from, _ := ParsePointer(o.From)
+		value, err := get(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, path, clone(value))
+	case OpTest:
+		value, err := decodeValue(o.Value)
+		if err != nil {
+			return nil, err
+		}
+		current, err := get(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !equal(current, value) {
+			return nil, ErrTestFailed
+		}
+		return doc, nil
+	}
+
+	return nil, ErrInvalidOp
+}
+
+// -------------------------------------------------------------------
+// Pointers
+// -------------------------------------------------------------------
+
+// ParsePointer splits an RFC 6901 JSON pointer into its unescaped
+// reference tokens (the empty pointer references the whole document).
+func ParsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, ErrInvalidPointer
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, t := range tokens {
+		// only "~0" and "~1" are valid escape sequences
+		if strings.Contains(strings.NewReplacer("~0", "", "~1", "").Replace(t), "~") {
+			return nil, ErrInvalidPointer
+		}
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(t)
+	}
+
+	return tokens, nil
+}
+
+// Pointer joins the provided reference tokens into an RFC 6901 JSON pointer
+// (escaping "~" and "/" in them).
+func Pointer(tokens ...string) string {
+	var b strings.Builder
+	for _, t := range tokens {
+		b.WriteString("/")
+		b.WriteString(strings.NewReplacer("~", "~0", "/", "~1").Replace(t))
+	}
+	return b.String()
+}
+
+// index parses an array index token (size is the max allowed index).
+func index(token string, size int) (int, error) {
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, ErrInvalidPointer
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || strconv.Itoa(i) != token {
+		return 0, ErrInvalidPointer
+	}
+	if i >= size {
+		return 0, ErrNotFound
+	}
+	return i, nil
+}
+
+func get(doc any, path []string) (any, error) {
+	for _, token := range path {
+		switch c := doc.(type) {
+		case map[string]any:
+			v, ok := c[token]
+			if !ok {
+				return nil, ErrNotFound
+			}
+			doc = v
+		case []any:
+			i, err := index(token, len(c))
+			if err != nil {
+				return nil, err
+			}
+			doc = c[i]
+		default:
+			return nil, ErrNotFound
+		}
+	}
+	return doc, nil
+}
+
+// update calls fn with the container of the last path token
+// and stores the returned container back into its parent
+// (the arrays may be reallocated).
+func update(doc any, path []string, fn func(container any, key string) (any, error)) (any, error) {
+	if len(path) == 1 {
+		return fn(doc, path[0])
+	}
+
+	child, err := get(doc, path[:1])
+	if err != nil {
+		return nil, err
+	}
+
+	child, err = update(child, path[1:], fn)
+	if err != nil {
+		return nil, err
+	}
+
+	switch c := doc.(type) {
+	case map[string]any:
+		c[path[0]] = child
+	case []any:
+		i, _ := index(path[0], len(c))
+		c[i] = child
+	}
+
+	return doc, nil
+}
+
+func add(doc any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return update(doc, path, func(container any, key string) (any, error) {
+		switch c := container.(type) {
+		case map[string]any:
+			c[key] = value
+			return c, nil
+		case []any:
+			i := len(c)
+			if key != "-" {
+				var err error
+				if i, err = index(key, len(c)+1); err != nil {
+					return nil, err
+				}
+			}
+			c = append(c, nil)
+			copy(c[i+1:], c[i:])
+			c[i] = value
+			return c, nil
+		default:
+			return nil, ErrNotFound
+		}
+	})
+}
+
+func remove(doc any, path []string) (any, any, error) {
+	if len(path) == 0 {
+		return nil, nil, fmt.Errorf("%w: the whole document can't be removed", ErrInvalidOp)
+	}
+
+	var removed any
+	doc, err := update(doc, path, func(container any, key string) (any, error) {
+		switch c := container.(type) {
+		case map[string]any:
+			v, ok := c[key]
+			if !ok {
+				return nil, ErrNotFound
+			}
+			removed = v
+			delete(c, key)
+			return c, nil
+		case []any:
+			i, err := index(key, len(c))
+			if err != nil {
+				return nil, err
+			}
+			removed = c[i]
+			return append(c[:i:i], c[i+1:]...), nil
+		default:
+			return nil, ErrNotFound
+		}
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return doc, removed, nil
+}
+
+// -------------------------------------------------------------------
+// Values
+// -------------------------------------------------------------------
+
+func decodeValue(raw json.RawMessage) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func clone(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		c := make(map[string]any, len(v))
+		for k, item := range v {
+			c[k] = clone(item)
+		}
+		return c
+	case []any:
+		c := make([]any, len(v))
+		for i, item := range v {
+			c[i] = clone(item)
+		}
+		return c
+	default:
+		return v
+	}
+}
+
+// equal compares two decoded JSON values
+// (the numbers are compared by value).
+func equal(a, b any) bool {
+	switch a := a.(type) {
+	case map[string]any:
+		b, ok := b.(map[string]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for k, v := range a {
+			other, ok := b[k]
+			if !ok || !equal(v, other) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		b, ok := b.([]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !equal(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	case json.Number:
+		b, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		af, errA := a.Float64()
+		bf, errB := b.Float64()
+		return errA == nil && errB == nil && af == bf
+	default:
+		return a == b
+	}
+}
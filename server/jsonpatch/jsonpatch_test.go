@@ -0,0 +1,269 @@
+package jsonpatch
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestApply(t *testing.T) {
+	scenarios := []struct {
+		name          string
+		doc           string
+		patch         string
+		expected      string
+		expectedError error
+	}{
+		{
+			"add an object member",
+			`{"foo": "bar"}`,
+			`[{"op": "add", "path": "/baz", "value": "qux"}]`,
+			`{"baz": "qux", "foo": "bar"}`,
+			nil,
+		},
+		{
+			"add an array element",
+			`{"foo": ["bar", "baz"]}`,
+			`[{"op": "add", "path": "/foo/1", "value": "qux"}]`,
+			`{"foo": ["bar", "qux", "baz"]}`,
+			nil,
+		},
+		{
+			"add to the end of an array",
+			`{"foo": ["bar"]}`,
+			`[{"op": "add", "path": "/foo/-", "value": ["abc", "def"]}]`,
+			`{"foo": ["bar", ["abc", "def"]]}`,
+			nil,
+		},
+		{
+			"add a null value",
+			`{"foo": "bar"}`,
+			`[{"op": "add", "path": "/baz", "value": null}]`,
+			`{"baz": null, "foo": "bar"}`,
+			nil,
+		},
+		{
+			"add to a nonexistent target",
+			`{"foo": "bar"}`,
+			`[{"op": "add", "path": "/baz/bat", "value": "qux"}]`,
+			``,
+			ErrNotFound,
+		},
+		{
+			"add out of the array bounds",
+			`{"foo": ["bar"]}`,
+			`[{"op": "add", "path": "/foo/2", "value": "qux"}]`,
+			``,
+			ErrNotFound,
+		},
+		{
+			"remove an object member",
+			`{"baz": "qux", "foo": "bar"}`,
+			`[{"op": "remove", "path": "/baz"}]`,
+			`{"foo": "bar"}`,
+			nil,
+		},
+		{
+			"remove an array element",
+			`{"foo": ["bar", "qux", "baz"]}`,
+			`[{"op": "remove", "path": "/foo/1"}]`,
+			`{"foo": ["bar", "baz"]}`,
+			nil,
+		},
+		{
+			"replace a value",
+			`{"baz": "qux", "foo": "bar"}`,
+			`[{"op": "replace", "path": "/baz", "value": "boo"}]`,
+			`{"baz": "boo", "foo": "bar"}`,
+			nil,
+		},
+		{
+			"replace a nonexistent value",
+			`{"foo": "bar"}`,
+			`[{"op": "replace", "path": "/baz", "value": "boo"}]`,
+			``,
+			ErrNotFound,
+		},
+		{
+			"move a value",
+			`{"foo": {"bar": "baz", "waldo": "fred"}, "qux": {"corge": "grault"}}`,
+			`[{"op": "move", "from": "/foo/waldo", "path": "/qux/thud"}]`,
+			`{"foo": {"bar": "baz"}, "qux": {"corge": "grault", "thud": "fred"}}`,
+			nil,
+		},
+		{
+			"move an array element",
+			`{"foo": ["all", "grass", "cows", "eat"]}`,
+			`[{"op": "move", "from": "/foo/1", "path": "/foo/3"}]`,
+			`{"foo": ["all", "cows", "eat", "grass"]}`,
+			nil,
+		},
+		{
+			"move into a child",
+			`{"foo": {"bar": {}}}`,
+			`[{"op": "move", "from": "/foo", "path": "/foo/bar/baz"}]`,
+			``,
+			ErrInvalidOp,
+		},
+		{
+			"copy a value",
+			`{"foo": {"bar": [1]}}`,
+			`[{"op": "copy", "from": "/foo", "path": "/baz"}, {"op": "add", "path": "/baz/bar/-", "value": 2}]`,
+			`{"baz": {"bar": [1, 2]}, "foo": {"bar": [1]}}`,
+			nil,
+		},
+		{
+			"test a value",
+			`{"baz": "qux", "foo": ["a", 2, "c"], "n": 10}`,
+			`[{"op": "test", "path": "/baz", "value": "qux"}, {"op": "test", "path": "/foo/1", "value": 2}, {"op": "test", "path": "/n", "value": 1e1}]`,
+			`{"baz": "qux", "foo": ["a", 2, "c"], "n": 10}`,
+			nil,
+		},
+		{
+			"test a different value",
+			`{"baz": "qux"}`,
+			`[{"op": "test", "path": "/baz", "value": "bar"}]`,
+			``,
+			ErrTestFailed,
+		},
+		{
+			"escaped pointers",
+			`{"/": 9, "~1": 10}`,
+			`[{"op": "test", "path": "/~01", "value": 10}, {"op": "remove", "path": "/~1"}]`,
+			`{"~1": 10}`,
+			nil,
+		},
+		{
+			"replace the whole document",
+			`{"foo": "bar"}`,
+			`[{"op": "replace", "path": "", "value": {"baz": 1}}]`,
+			`{"baz": 1}`,
+			nil,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			patch, err := Decode([]byte(s.patch))
+			if err != nil {
+				t.Fatalf("Failed to decode the patch: %v", err)
+			}
+
+			result, err := patch.Apply([]byte(s.doc))
+			if !errors.Is(err, s.expectedError) {
+				t.Fatalf("Expected error %v, got %v", s.expectedError, err)
+			}
+			if err != nil {
+				return
+			}
+
+			if !jsonEqual(t, result, []byte(s.expected)) {
+				t.Fatalf("Expected %s, got %s", s.expected, result)
+			}
+		})
+	}
+}
+
+func TestApplyKeepsNumbers(t *testing.T) {
+	patch, _ := Decode([]byte(`[{"op": "add", "path": "/n", "value": 1.50}]`))
+
+	result, err := patch.Apply([]byte(`{"id": 12345678901234567890}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := `{"id":12345678901234567890,"n":1.50}`; string(result) != expected {
+		t.Fatalf("Expected %s, got %s", expected, result)
+	}
+}
+
+func TestApplyOpError(t *testing.T) {
+	patch, _ := Decode([]byte(`[{"op": "add", "path": "/a", "value": 1}, {"op": "remove", "path": "/b"}]`))
+
+	_, err := patch.Apply([]byte(`{}`))
+
+	var opErr *OpError
+	if !errors.As(err, &opErr) || opErr.Index != 1 {
+		t.Fatalf("Expected an OpError for the operation 1, got %v", err)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	scenarios := []struct {
+		patch       string
+		expectError bool
+	}{
+		{`[]`, false},
+		{`{"op": "add"}`, true},
+		{`[null]`, true},
+		{`[{"op": "unknown", "path": "/a"}]`, true},
+		{`[{"op": "add", "path": "/a"}]`, true},
+		{`[{"op": "add", "path": "a", "value": 1}]`, true},
+		{`[{"op": "remove", "path": "/a~2"}]`, true},
+		{`[{"op": "move", "path": "/a"}]`, false},
+		{`[{"op": "copy", "from": "b", "path": "/a"}]`, true},
+	}
+
+	for _, s := range scenarios {
+		_, err := Decode([]byte(s.patch))
+		if hasErr := err != nil; hasErr != s.expectError {
+			t.Errorf("[%s] Expected hasErr %v, got %v (%v)", s.patch, s.expectError, hasErr, err)
+		}
+	}
+}
+
+func TestModified(t *testing.T) {
+	scenarios := []struct {
+		op       Operation
+		expected []string
+	}{
+		{Operation{Op: OpAdd, Path: "/a"}, []string{"/a"}},
+		{Operation{Op: OpCopy, From: "/b", Path: "/a"}, []string{"/a"}},
+		{Operation{Op: OpMove, From: "/b", Path: "/a"}, []string{"/b", "/a"}},
+		{Operation{Op: OpTest, Path: "/a"}, nil},
+	}
+
+	for _, s := range scenarios {
+		if modified := s.op.Modified(); !slices.Equal(modified, s.expected) {
+			t.Errorf("[%s] Expected %v, got %v", s.op.Op, s.expected, modified)
+		}
+	}
+}
+
+func TestPointer(t *testing.T) {
+	scenarios := []struct {
+		tokens   []string
+		expected string
+	}{
+		{nil, ""},
+		{[]string{"ui", "items", "a/b~c"}, "/ui/items/a~1b~0c"},
+		{[]string{""}, "/"},
+	}
+
+	for _, s := range scenarios {
+		pointer := Pointer(s.tokens...)
+		if pointer != s.expected {
+			t.Fatalf("Expected %q, got %q", s.expected, pointer)
+		}
+
+		tokens, err := ParsePointer(pointer)
+		if err != nil || !slices.Equal(tokens, s.tokens) {
+			t.Fatalf("Expected the %q tokens to round trip, got %v (%v)", pointer, tokens, err)
+		}
+	}
+}
+
+func jsonEqual(t *testing.T, a, b []byte) bool {
+	t.Helper()
+
+	va, err := decodeValue(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vb, err := decodeValue(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return equal(va, vb)
+}
@@ -0,0 +1,132 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// anthropicVersion is the Anthropic API version the requests are sent with.
+const anthropicVersion = "2023-06-01"
+
+// anthropicProvider sends the requests to the Anthropic messages API.
+type anthropicProvider struct {
+	config  Config
+	baseUrl string
+	apiKey  string
+}
+
+func (p *anthropicProvider) Name() string {
+	return ProviderAnthropic
+}
+
+func (p *anthropicProvider) Model() string {
+	return p.config.ModelName()
+}
+
+func (p *anthropicProvider) Chat(ctx context.Context, req *Request) (*Response, error) {
+	var result struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+	}
+	if err := postJSON(ctx, p.baseUrl+"/messages", p.header(), p.body(req), &result); err != nil {
+		return nil, err
+	}
+
+	var content strings.Builder
+	for _, block := range result.Content {
+		if block.Type == "text" {
+			content.WriteString(block.Text)
+		}
+	}
+	if content.Len() == 0 {
+		return nil, ErrEmptyResponse
+	}
+
+	return &Response{Content: content.String()}, nil
+}
+
+func (p *anthropicProvider) ChatStream(ctx context.Context, req *Request, onDelta DeltaFunc) (*Response, error) {
+	body := p.body(req)
+	body["stream"] = true
+
+	var content strings.Builder
+	err := postStream(ctx, p.baseUrl+"/messages", p.header(), body, func(e *sseEvent) error {
+		switch e.Name {
+		case "content_block_delta":
+			var chunk struct {
+				Delta struct {
+					Type string `json:"type"`
+					Text string `json:"text"`
+				} `json:"delta"`
+			}
+			if err := json.Unmarshal([]byte(e.Data), &chunk); err != nil {
+				return err
+			}
+			if chunk.Delta.Type != "text_delta" || chunk.Delta.Text == "" {
+				return nil
+			}
+			content.WriteString(chunk.Delta.Text)
+			return onDelta(chunk.Delta.Text)
+		case "error":
+			var chunk struct {
+				Error struct {
+					Message string `json:"message"`
+				} `json:"error"`
+			}
+			json.Unmarshal([]byte(e.Data), &chunk)
+			return errors.New(chunk.Error.Message)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if content.Len() == 0 {
+		return nil, ErrEmptyResponse
+	}
+
+	return &Response{Content: content.String()}, nil
+}
+
+func (p *anthropicProvider) header() http.Header {
+	header := http.Header{}
+	header.Set("x-api-key", p.apiKey)
+	header.Set("anthropic-version", anthropicVersion)
+	return header
+}
+
+func (p *anthropicProvider) body(req *Request) map[string]any {
+	maxTokens := req.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = DefaultMaxTokens
+	}
+
+	// the system prompt is a request param and not a message
+	system := []string{}
+	messages := make([]Message, 0, len(req.Messages))
+	for _, m := range req.Messages {
+		if m.Role == RoleSystem {
+			system = append(system, m.Content)
+			continue
+		}
+		messages = append(messages, m)
+	}
+
+	body := map[string]any{
+		"model":       p.Model(),
+		"messages":    messages,
+		"temperature": p.config.TemperatureValue(),
+		"max_tokens":  maxTokens,
+	}
+	if len(system) > 0 {
+		body["system"] = strings.Join(system, "\n\n")
+	}
+
+	return body
+}
@@ -0,0 +1,259 @@
+// Package llm implements the chat completion providers (OpenAI,
+// OpenAI-compatible servers and Anthropic) used by the AI assistant.
+//
+// Example usage:
+//
+//	provider, err := llm.New(llm.Config{Provider: llm.ProviderAnthropic}, apiKey)
+//	...
+//	resp, err := provider.Chat(ctx, &llm.Request{Messages: messages, JSON: true})
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+	"github.com/pocketbase/pocketbase/tools/list"
+)
+
+const (
+	// ProviderOpenAI is the OpenAI API (api key or ChatGPT/Codex tokens).
+	ProviderOpenAI = "openai"
+	// ProviderOpenAICompatible is any server implementing the OpenAI chat
+	// completions API (eg. a self-hosted Ollama or vLLM).
+	ProviderOpenAICompatible = "openai_compatible"
+	// ProviderAnthropic is the Anthropic messages API.
+	ProviderAnthropic = "anthropic"
+)
+
+// Providers lists every supported provider.
+var Providers = []string{
+	ProviderOpenAI,
+	ProviderOpenAICompatible,
+	ProviderAnthropic,
+}
+
+// DefaultTemperature is the sampling temperature used when none is configured.
+const DefaultTemperature = 0.7
+
+// DefaultMaxTokens is the max number of generated tokens when none is requested.
+const DefaultMaxTokens = 16000
+
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// HttpClient is a base HTTP client interface (usually used for test purposes).
+type HttpClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Client is the client used to send the provider requests.
+var Client HttpClient = http.DefaultClient
+
+// Config defines the admin configurable provider options.
+//
+// The empty fields fallback to the provider defaults.
+type Config struct {
+	Provider    string   `json:"provider"`
+	Model       string   `json:"model"`
+	BaseUrl     string   `json:"base_url"`
+	Temperature *float64 `json:"temperature"`
+}
+
+// Validate makes Config validatable by implementing [validation.Validatable] interface.
+func (c Config) Validate() error {
+	maxTemperature := 2.0
+	if c.Provider == ProviderAnthropic {
+		maxTemperature = 1.0
+	}
+
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Provider, validation.In(list.ToInterfaceSlice(Providers)...)),
+		validation.Field(&c.Model, validation.Length(0, 255)),
+		validation.Field(&c.BaseUrl,
+			validation.When(c.Provider == ProviderOpenAICompatible, validation.Required),
+			is.URL,
+		),
+		validation.Field(&c.Temperature, validation.Min(0.0), validation.Max(maxTemperature)),
+	)
+}
+
+// ProviderName returns the configured provider (OpenAI by default).
+func (c Config) ProviderName() string {
+	if c.Provider == "" {
+		return ProviderOpenAI
+	}
+	return c.Provider
+}
+
+// ModelName returns the configured model or the provider default one.
+func (c Config) ModelName() string {
+	if c.Model != "" {
+		return c.Model
+	}
+
+	switch c.ProviderName() {
+	case ProviderAnthropic:
+		return "claude-sonnet-4-20250514"
+	case ProviderOpenAICompatible:
+		return ""
+	default:
+		return "gpt-4o"
+	}
+}
+
+// TemperatureValue returns the configured temperature or [DefaultTemperature].
+func (c Config) TemperatureValue() float64 {
+	if c.Temperature != nil {
+		return *c.Temperature
+	}
+	return DefaultTemperature
+}
+
+// Message is a single chat message.
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Request is a chat completion request.
+//
+// The system messages are sent the way the provider expects them
+// (eg. as the "system" param of the Anthropic API).
+type Request struct {
+	Messages  []Message
+	MaxTokens int
+
+	// JSON asks the provider to answer with a JSON object
+	// (when supported, the prompt should ask for it too).
+	JSON bool
+}
+
+// Response is a chat completion response.
+type Response struct {
+	Content string
+}
+
+// Provider is a chat completion provider.
+type Provider interface {
+	// Name returns the provider name (see [Providers]).
+	Name() string
+
+	// Model returns the model the requests are sent to.
+	Model() string
+
+	// Chat sends the request and returns the generated message.
+	Chat(ctx context.Context, req *Request) (*Response, error)
+
+	// ChatStream sends the request streaming the generated message
+	// chunks to onDelta and returns the complete message.
+	//
+	// The stream is stopped as soon as ctx is cancelled.
+	ChatStream(ctx context.Context, req *Request, onDelta DeltaFunc) (*Response, error)
+}
+
+// ErrEmptyResponse is returned when the provider didn't generate any message.
+var ErrEmptyResponse = errors.New("the AI returned no response")
+
+// StatusError is returned when the provider API responds with an error status.
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("request failed with status %d: %s", e.StatusCode, e.Body)
+}
+
+// IsUnauthorized checks whether err is a provider 401 response.
+func IsUnauthorized(err error) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusUnauthorized
+}
+
+// New creates the provider defined by config, authenticated with
+// the provided api key or token (optional for OpenAI-compatible servers).
+func New(config Config, apiKey string) (Provider, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	baseUrl := strings.TrimSuffix(config.BaseUrl, "/")
+
+	switch config.ProviderName() {
+	case ProviderAnthropic:
+		if apiKey == "" {
+			return nil, errors.New("no AI authentication configured")
+		}
+		if baseUrl == "" {
+			baseUrl = "https://api.anthropic.com/v1"
+		}
+		return &anthropicProvider{config: config, baseUrl: baseUrl, apiKey: apiKey}, nil
+	case ProviderOpenAICompatible:
+		if config.ModelName() == "" {
+			return nil, errors.New("a model is required for the OpenAI-compatible provider")
+		}
+		return &openaiProvider{name: ProviderOpenAICompatible, config: config, baseUrl: baseUrl, apiKey: apiKey}, nil
+	default:
+		if apiKey == "" {
+			return nil, errors.New("no AI authentication configured")
+		}
+		if baseUrl == "" {
+			baseUrl = "https://api.openai.com/v1"
+		}
+		return &openaiProvider{name: ProviderOpenAI, config: config, baseUrl: baseUrl, apiKey: apiKey}, nil
+	}
+}
+
+// postJSON sends body as JSON to url and decodes the JSON response into result.
+func postJSON(ctx context.Context, url string, header http.Header, body any, result any) error {
+	rawBody, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(rawBody))
+	if err != nil {
+		return err
+	}
+	req.Header = header
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &StatusError{StatusCode: resp.StatusCode, Body: string(respBody)}
+	}
+
+	return json.Unmarshal(respBody, result)
+}
+
+// ExtractJSON returns the JSON object of a generated message, removing
+// the markdown code fences or text some models wrap it with.
+func ExtractJSON(content string) string {
+	start := strings.Index(content, "{")
+	end := strings.LastIndex(content, "}")
+	if start < 0 || end < start {
+		return content
+	}
+	return content[start : end+1]
+}
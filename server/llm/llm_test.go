@@ -0,0 +1,249 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestConfigValidate(t *testing.T) {
+	temperature := func(v float64) *float64 { return &v }
+
+	scenarios := []struct {
+		name        string
+		config      Config
+		expectError bool
+	}{
+		{"defaults", Config{}, false},
+		{"unknown provider", Config{Provider: "unknown"}, true},
+		{"compatible without base url", Config{Provider: ProviderOpenAICompatible}, true},
+		{"compatible with base url", Config{Provider: ProviderOpenAICompatible, BaseUrl: "http://localhost:11434/v1"}, false},
+		{"invalid base url", Config{BaseUrl: "not a url"}, true},
+		{"openai temperature", Config{Temperature: temperature(1.5)}, false},
+		{"anthropic temperature", Config{Provider: ProviderAnthropic, Temperature: temperature(1.5)}, true},
+		{"negative temperature", Config{Temperature: temperature(-1)}, true},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			err := s.config.Validate()
+			if hasErr := err != nil; hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+		})
+	}
+}
+
+func TestOpenAICompatibleChat(t *testing.T) {
+	var received map[string]any
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("Unexpected path %q", r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			t.Errorf("Expected no Authorization header, got %q", auth)
+		}
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &received)
+		w.Write([]byte(`{"choices":[{"message":{"content":"{\"ok\":true}"}}]}`))
+	}))
+	defer server.Close()
+
+	provider, err := New(Config{Provider: ProviderOpenAICompatible, BaseUrl: server.URL + "/v1/", Model: "llama3"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := provider.Chat(context.Background(), &Request{
+		Messages: []Message{{Role: RoleSystem, Content: "sys"}, {Role: RoleUser, Content: "hi"}},
+		JSON:     true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.Content != `{"ok":true}` {
+		t.Fatalf("Unexpected content %q", resp.Content)
+	}
+	if received["model"] != "llama3" || received["temperature"] != DefaultTemperature {
+		t.Fatalf("Unexpected request %v", received)
+	}
+	if _, ok := received["response_format"]; !ok {
+		t.Fatalf("Expected the json response_format, got %v", received)
+	}
+}
+
+func TestAnthropicChat(t *testing.T) {
+	var received map[string]any
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/messages" {
+			t.Errorf("Unexpected path %q", r.URL.Path)
+		}
+		if key := r.Header.Get("x-api-key"); key != "test" {
+			t.Errorf("Expected the api key header, got %q", key)
+		}
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &received)
+		w.Write([]byte(`{"content":[{"type":"text","text":"a"},{"type":"text","text":"b"}]}`))
+	}))
+	defer server.Close()
+
+	provider, err := New(Config{Provider: ProviderAnthropic, BaseUrl: server.URL}, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := provider.Chat(context.Background(), &Request{
+		Messages: []Message{{Role: RoleSystem, Content: "sys"}, {Role: RoleUser, Content: "hi"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.Content != "ab" {
+		t.Fatalf("Unexpected content %q", resp.Content)
+	}
+	if received["system"] != "sys" {
+		t.Fatalf("Expected the system param, got %v", received["system"])
+	}
+	if messages, _ := received["messages"].([]any); len(messages) != 1 {
+		t.Fatalf("Expected only the user message, got %v", received["messages"])
+	}
+}
+
+func TestStatusError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":"invalid key"}`))
+	}))
+	defer server.Close()
+
+	provider, err := New(Config{BaseUrl: server.URL}, "key")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = provider.Chat(context.Background(), &Request{})
+	if !IsUnauthorized(err) {
+		t.Fatalf("Expected an unauthorized error, got %v", err)
+	}
+}
+
+func TestExtractJSON(t *testing.T) {
+	scenarios := map[string]string{
+		`{"a":1}`:                      `{"a":1}`,
+		"```json\n{\"a\":1}\n```":      `{"a":1}`,
+		"Here it is: {\"a\":{}} done.": `{"a":{}}`,
+		"no json":                      "no json",
+	}
+
+	for content, expected := range scenarios {
+		if result := ExtractJSON(content); result != expected {
+			t.Errorf("Expected %q for %q, got %q", expected, content, result)
+		}
+	}
+}
+
+func TestOpenAIChatStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), `"stream":true`) {
+			t.Errorf("Expected a stream request, got %s", body)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("data: {\"choices\":[{\"delta\":{\"role\":\"assistant\"}}]}\n\n"))
+		w.Write([]byte("data: {\"choices\":[{\"delta\":{\"content\":\"{\\\"a\\\"\"}}]}\n\n"))
+		w.Write([]byte("data: {\"choices\":[{\"delta\":{\"content\":\":1}\"}}]}\n\n"))
+		w.Write([]byte("data: [DONE]\n\n"))
+	}))
+	defer server.Close()
+
+	provider, err := New(Config{BaseUrl: server.URL}, "key")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deltas := []string{}
+	resp, err := provider.ChatStream(context.Background(), &Request{}, func(delta string) error {
+		deltas = append(deltas, delta)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(deltas) != 2 || resp.Content != `{"a":1}` {
+		t.Fatalf("Unexpected deltas %v (content %q)", deltas, resp.Content)
+	}
+}
+
+func TestAnthropicChatStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("event: message_start\ndata: {\"type\":\"message_start\"}\n\n"))
+		w.Write([]byte("event: content_block_delta\ndata: {\"delta\":{\"type\":\"text_delta\",\"text\":\"he\"}}\n\n"))
+		w.Write([]byte("event: ping\ndata: {\"type\":\"ping\"}\n\n"))
+		w.Write([]byte("event: content_block_delta\ndata: {\"delta\":{\"type\":\"text_delta\",\"text\":\"llo\"}}\n\n"))
+		w.Write([]byte("event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n"))
+	}))
+	defer server.Close()
+
+	provider, err := New(Config{Provider: ProviderAnthropic, BaseUrl: server.URL}, "key")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deltas := []string{}
+	resp, err := provider.ChatStream(context.Background(), &Request{}, func(delta string) error {
+		deltas = append(deltas, delta)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(deltas) != 2 || resp.Content != "hello" {
+		t.Fatalf("Unexpected deltas %v (content %q)", deltas, resp.Content)
+	}
+}
+
+func TestChatStreamCancel(t *testing.T) {
+	done := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("data: {\"choices\":[{\"delta\":{\"content\":\"a\"}}]}\n\n"))
+		w.(http.Flusher).Flush()
+
+		// never ending stream
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}))
+	defer server.Close()
+	defer close(done)
+
+	provider, err := New(Config{BaseUrl: server.URL}, "key")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, err = provider.ChatStream(ctx, &Request{}, func(delta string) error {
+		cancel()
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected a canceled error, got %v", err)
+	}
+}
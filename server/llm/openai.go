@@ -0,0 +1,111 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// openaiProvider sends the requests to the OpenAI chat completions API
+// (or to a server implementing it).
+type openaiProvider struct {
+	name    string
+	config  Config
+	baseUrl string
+	apiKey  string
+}
+
+func (p *openaiProvider) Name() string {
+	return p.name
+}
+
+func (p *openaiProvider) Model() string {
+	return p.config.ModelName()
+}
+
+func (p *openaiProvider) Chat(ctx context.Context, req *Request) (*Response, error) {
+	var result struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	if err := postJSON(ctx, p.baseUrl+"/chat/completions", p.header(), p.body(req), &result); err != nil {
+		return nil, err
+	}
+
+	if len(result.Choices) == 0 {
+		return nil, ErrEmptyResponse
+	}
+
+	return &Response{Content: result.Choices[0].Message.Content}, nil
+}
+
+func (p *openaiProvider) ChatStream(ctx context.Context, req *Request, onDelta DeltaFunc) (*Response, error) {
+	body := p.body(req)
+	body["stream"] = true
+
+	var content strings.Builder
+	err := postStream(ctx, p.baseUrl+"/chat/completions", p.header(), body, func(e *sseEvent) error {
+		if e.Data == "[DONE]" {
+			return nil
+		}
+
+		var chunk struct {
+			Choices []struct {
+				Delta struct {
+					Content string `json:"content"`
+				} `json:"delta"`
+			} `json:"choices"`
+		}
+		if err := json.Unmarshal([]byte(e.Data), &chunk); err != nil {
+			return err
+		}
+
+		if len(chunk.Choices) == 0 || chunk.Choices[0].Delta.Content == "" {
+			return nil
+		}
+
+		delta := chunk.Choices[0].Delta.Content
+		content.WriteString(delta)
+		return onDelta(delta)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if content.Len() == 0 {
+		return nil, ErrEmptyResponse
+	}
+
+	return &Response{Content: content.String()}, nil
+}
+
+func (p *openaiProvider) header() http.Header {
+	header := http.Header{}
+	if p.apiKey != "" {
+		header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+	return header
+}
+
+func (p *openaiProvider) body(req *Request) map[string]any {
+	maxTokens := req.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = DefaultMaxTokens
+	}
+
+	body := map[string]any{
+		"model":       p.Model(),
+		"messages":    req.Messages,
+		"temperature": p.config.TemperatureValue(),
+		"max_tokens":  maxTokens,
+	}
+	if req.JSON {
+		body["response_format"] = map[string]any{"type": "json_object"}
+	}
+
+	return body
+}
@@ -0,0 +1,96 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// DeltaFunc is called with every generated text chunk of a streamed
+// completion. A returned error stops the stream.
+type DeltaFunc func(delta string) error
+
+// sseEvent is a single server-sent event.
+type sseEvent struct {
+	Name string
+	Data string
+}
+
+// postStream sends body as JSON to url and calls fn with every
+// server-sent event of the response until the stream ends
+// or ctx is cancelled.
+func postStream(ctx context.Context, url string, header http.Header, body any, fn func(e *sseEvent) error) error {
+	rawBody, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(rawBody))
+	if err != nil {
+		return err
+	}
+	req.Header = header
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return &StatusError{StatusCode: resp.StatusCode, Body: string(respBody)}
+	}
+
+	return readEvents(resp.Body, fn)
+}
+
+// readEvents parses the server-sent events stream of r.
+func readEvents(r io.Reader, fn func(e *sseEvent) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	event := &sseEvent{}
+	data := []string{}
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if line == "" {
+			if len(data) > 0 {
+				event.Data = strings.Join(data, "\n")
+				if err := fn(event); err != nil {
+					return err
+				}
+			}
+			event = &sseEvent{}
+			data = data[:0]
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event.Name = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	// the stream may end without a trailing blank line
+	if len(data) > 0 {
+		event.Data = strings.Join(data, "\n")
+		return fn(event)
+	}
+
+	return nil
+}
@@ -0,0 +1,27 @@
+package migrations
+
+import (
+	"github.com/pocketbase/dbx"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		_, err := db.NewQuery(`
+		CREATE TABLE {{_pbl_datasources}} (
+			[[id]]        TEXT PRIMARY KEY NOT NULL,
+			[[name]]      TEXT NOT NULL,
+			[[type]]      TEXT NOT NULL,
+			[[config]]    JSON DEFAULT "{}" NOT NULL,
+			[[created]]   TEXT DEFAULT (strftime('%Y-%m-%d %H:%M:%fZ')) NOT NULL,
+			[[updated]]   TEXT DEFAULT (strftime('%Y-%m-%d %H:%M:%fZ')) NOT NULL
+		);
+		`).Execute()
+
+		return err
+	}, func(db dbx.Builder) error {
+		_, err := db.NewQuery("DROP TABLE IF EXISTS {{_pbl_datasources}}").Execute()
+
+		return err
+	})
+}
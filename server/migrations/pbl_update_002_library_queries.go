@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	"github.com/pocketbase/dbx"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		_, err := db.NewQuery(`
+		CREATE TABLE {{_pbl_library_queries}} (
+			[[id]]              TEXT PRIMARY KEY NOT NULL,
+			[[name]]            TEXT NOT NULL,
+			[[editDSL]]         JSON DEFAULT "{}" NOT NULL,
+			[[publishedDSL]]    JSON DEFAULT "{}" NOT NULL,
+			[[tag]]             TEXT DEFAULT "" NOT NULL,
+			[[commitMessage]]   TEXT DEFAULT "" NOT NULL,
+			[[published]]       TEXT DEFAULT "" NOT NULL,
+			[[created]]         TEXT DEFAULT (strftime('%Y-%m-%d %H:%M:%fZ')) NOT NULL,
+			[[updated]]         TEXT DEFAULT (strftime('%Y-%m-%d %H:%M:%fZ')) NOT NULL
+		);
+		`).Execute()
+
+		return err
+	}, func(db dbx.Builder) error {
+		_, err := db.NewQuery("DROP TABLE IF EXISTS {{_pbl_library_queries}}").Execute()
+
+		return err
+	})
+}
@@ -0,0 +1,25 @@
+package migrations
+
+import (
+	"github.com/pocketbase/dbx"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		_, err := db.NewQuery(`
+		ALTER TABLE {{_pbl_folders}} ADD COLUMN [[parent]] TEXT DEFAULT NULL
+			REFERENCES {{_pbl_folders}} ([[id]]) ON UPDATE CASCADE ON DELETE CASCADE;
+
+		CREATE INDEX _pbl_folders_parent_idx ON {{_pbl_folders}} ([[parent]]);
+		`).Execute()
+
+		return err
+	}, func(db dbx.Builder) error {
+		// sqlite cannot drop a column used in a foreign key constraint,
+		// so the (unused) parent column is kept
+		_, err := db.NewQuery("DROP INDEX IF EXISTS _pbl_folders_parent_idx").Execute()
+
+		return err
+	})
+}
@@ -0,0 +1,20 @@
+package migrations
+
+import (
+	"github.com/pocketbase/dbx"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		_, err := db.NewQuery(`
+		ALTER TABLE {{_pbl_apps}} ADD COLUMN [[roles]] JSON DEFAULT "{}" NOT NULL;
+		`).Execute()
+
+		return err
+	}, func(db dbx.Builder) error {
+		_, err := db.NewQuery("ALTER TABLE {{_pbl_apps}} DROP COLUMN [[roles]]").Execute()
+
+		return err
+	})
+}
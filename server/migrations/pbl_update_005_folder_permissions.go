@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	"github.com/pocketbase/dbx"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		_, err := db.NewQuery(`
+		ALTER TABLE {{_pbl_folders}} ADD COLUMN [[allUsers]] BOOLEAN DEFAULT FALSE NOT NULL;
+		ALTER TABLE {{_pbl_folders}} ADD COLUMN [[groups]] JSON DEFAULT "[]" NOT NULL;
+		ALTER TABLE {{_pbl_folders}} ADD COLUMN [[users]] JSON DEFAULT "[]" NOT NULL;
+		`).Execute()
+
+		return err
+	}, func(db dbx.Builder) error {
+		_, err := db.NewQuery(`
+		ALTER TABLE {{_pbl_folders}} DROP COLUMN [[allUsers]];
+		ALTER TABLE {{_pbl_folders}} DROP COLUMN [[groups]];
+		ALTER TABLE {{_pbl_folders}} DROP COLUMN [[users]];
+		`).Execute()
+
+		return err
+	})
+}
@@ -0,0 +1,72 @@
+package migrations
+
+import (
+	"github.com/pedrozadotdev/pocketblocks/server/models"
+	"github.com/pocketbase/dbx"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+type snapshotDslRow struct {
+	Id  string `db:"id"`
+	Dsl string `db:"dsl"`
+}
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		if _, err := db.NewQuery(`
+		ALTER TABLE {{_pbl_app_snapshots}} ADD COLUMN [[encoding]] TEXT DEFAULT "" NOT NULL;
+		`).Execute(); err != nil {
+			return err
+		}
+
+		// compress the existing snapshots
+		rows := []snapshotDslRow{}
+		if err := db.Select("id", "dsl").From("_pbl_app_snapshots").All(&rows); err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			compressed, err := models.CompressSnapshotDsl(row.Dsl)
+			if err != nil {
+				return err
+			}
+
+			if _, err := db.Update(
+				"_pbl_app_snapshots",
+				dbx.Params{"dsl": compressed, "encoding": models.SnapshotEncodingGzip},
+				dbx.HashExp{"id": row.Id},
+			).Execute(); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, func(db dbx.Builder) error {
+		rows := []snapshotDslRow{}
+		if err := db.Select("id", "dsl").
+			From("_pbl_app_snapshots").
+			Where(dbx.HashExp{"encoding": models.SnapshotEncodingGzip}).
+			All(&rows); err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			dsl, err := models.DecompressSnapshotDsl(row.Dsl)
+			if err != nil {
+				return err
+			}
+
+			if _, err := db.Update(
+				"_pbl_app_snapshots",
+				dbx.Params{"dsl": dsl},
+				dbx.HashExp{"id": row.Id},
+			).Execute(); err != nil {
+				return err
+			}
+		}
+
+		_, err := db.NewQuery("ALTER TABLE {{_pbl_app_snapshots}} DROP COLUMN [[encoding]]").Execute()
+
+		return err
+	})
+}
@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	"github.com/pocketbase/dbx"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		// templateId is a plain column (no foreign key), so the source
+		// of an app is kept even after its template is deleted
+		_, err := db.NewQuery(`
+		ALTER TABLE {{_pbl_apps}} ADD COLUMN [[isTemplate]] BOOLEAN DEFAULT FALSE NOT NULL;
+		ALTER TABLE {{_pbl_apps}} ADD COLUMN [[templateId]] TEXT DEFAULT NULL;
+		`).Execute()
+
+		return err
+	}, func(db dbx.Builder) error {
+		_, err := db.NewQuery(`
+		ALTER TABLE {{_pbl_apps}} DROP COLUMN [[isTemplate]];
+		ALTER TABLE {{_pbl_apps}} DROP COLUMN [[templateId]];
+		`).Execute()
+
+		return err
+	})
+}
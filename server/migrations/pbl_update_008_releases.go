@@ -0,0 +1,36 @@
+package migrations
+
+import (
+	"github.com/pocketbase/dbx"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		_, err := db.NewQuery(`
+		CREATE TABLE {{_pbl_releases}} (
+			[[id]]            TEXT PRIMARY KEY NOT NULL,
+			[[app]]           TEXT NOT NULL,
+			[[dsl]]           JSON DEFAULT "{}" NOT NULL,
+			[[status]]        TEXT NOT NULL,
+			[[requestedBy]]   TEXT DEFAULT "" NOT NULL,
+			[[reviewedBy]]    TEXT DEFAULT "" NOT NULL,
+			[[scheduledAt]]   TEXT DEFAULT "" NOT NULL,
+			[[appliedAt]]     TEXT DEFAULT "" NOT NULL,
+			[[created]]       TEXT DEFAULT (strftime('%Y-%m-%d %H:%M:%fZ')) NOT NULL,
+			[[updated]]       TEXT DEFAULT (strftime('%Y-%m-%d %H:%M:%fZ')) NOT NULL,
+			---
+			FOREIGN KEY ([[app]]) REFERENCES {{_pbl_apps}} ([[id]]) ON UPDATE CASCADE ON DELETE CASCADE
+		);
+
+		CREATE INDEX _pbl_releases_app_idx ON {{_pbl_releases}} ([[app]]);
+		CREATE INDEX _pbl_releases_status_idx ON {{_pbl_releases}} ([[status]]);
+		`).Execute()
+
+		return err
+	}, func(db dbx.Builder) error {
+		_, err := db.NewQuery("DROP TABLE IF EXISTS {{_pbl_releases}}").Execute()
+
+		return err
+	})
+}
@@ -0,0 +1,61 @@
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/pocketbase/dbx"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		if _, err := db.NewQuery(`
+		ALTER TABLE {{_pbl_releases}} ADD COLUMN [[version]] TEXT DEFAULT "" NOT NULL;
+		ALTER TABLE {{_pbl_releases}} ADD COLUMN [[notes]] TEXT DEFAULT "" NOT NULL;
+		ALTER TABLE {{_pbl_apps}} ADD COLUMN [[release]] TEXT DEFAULT "" NOT NULL;
+		`).Execute(); err != nil {
+			return err
+		}
+
+		// number the existing releases of every app as 1.0.0, 1.0.1, ...
+		rows := []struct {
+			Id    string `db:"id"`
+			AppId string `db:"app"`
+		}{}
+		if err := db.Select("id", "app").
+			From("_pbl_releases").
+			OrderBy("app ASC", "created ASC").
+			All(&rows); err != nil {
+			return err
+		}
+
+		patch := map[string]int{}
+		for _, row := range rows {
+			version := fmt.Sprintf("1.0.%d", patch[row.AppId])
+			patch[row.AppId]++
+
+			if _, err := db.Update("_pbl_releases", dbx.Params{"version": version}, dbx.HashExp{"id": row.Id}).Execute(); err != nil {
+				return err
+			}
+		}
+
+		// the last applied release of every app is the live one
+		_, err := db.NewQuery(`
+		UPDATE {{_pbl_apps}} SET [[release]] = COALESCE((
+			SELECT [[id]] FROM {{_pbl_releases}}
+			WHERE [[app]] = {{_pbl_apps}}.[[id]] AND [[status]] = 'applied'
+			ORDER BY [[appliedAt]] DESC LIMIT 1
+		), '');
+		`).Execute()
+
+		return err
+	}, func(db dbx.Builder) error {
+		_, err := db.NewQuery(`
+		ALTER TABLE {{_pbl_releases}} DROP COLUMN [[version]];
+		ALTER TABLE {{_pbl_releases}} DROP COLUMN [[notes]];
+		ALTER TABLE {{_pbl_apps}} DROP COLUMN [[release]];
+		`).Execute()
+
+		return err
+	})
+}
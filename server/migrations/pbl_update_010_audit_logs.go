@@ -0,0 +1,39 @@
+package migrations
+
+import (
+	"github.com/pocketbase/dbx"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		_, err := db.NewQuery(`
+		CREATE TABLE {{_pbl_audit_logs}} (
+			[[id]]            TEXT PRIMARY KEY NOT NULL,
+			[[actor]]         TEXT DEFAULT "" NOT NULL,
+			[[actorType]]     TEXT DEFAULT "" NOT NULL,
+			[[actorName]]     TEXT DEFAULT "" NOT NULL,
+			[[action]]        TEXT NOT NULL,
+			[[targetType]]    TEXT DEFAULT "" NOT NULL,
+			[[target]]        TEXT DEFAULT "" NOT NULL,
+			[[targetName]]    TEXT DEFAULT "" NOT NULL,
+			[[ip]]            TEXT DEFAULT "" NOT NULL,
+			[[before]]        JSON DEFAULT "null" NOT NULL,
+			[[after]]         JSON DEFAULT "null" NOT NULL,
+			[[created]]       TEXT DEFAULT (strftime('%Y-%m-%d %H:%M:%fZ')) NOT NULL,
+			[[updated]]       TEXT DEFAULT (strftime('%Y-%m-%d %H:%M:%fZ')) NOT NULL
+		);
+
+		CREATE INDEX _pbl_audit_logs_action_idx ON {{_pbl_audit_logs}} ([[action]]);
+		CREATE INDEX _pbl_audit_logs_target_idx ON {{_pbl_audit_logs}} ([[targetType]], [[target]]);
+		CREATE INDEX _pbl_audit_logs_actor_idx ON {{_pbl_audit_logs}} ([[actor]]);
+		CREATE INDEX _pbl_audit_logs_created_idx ON {{_pbl_audit_logs}} ([[created]]);
+		`).Execute()
+
+		return err
+	}, func(db dbx.Builder) error {
+		_, err := db.NewQuery("DROP TABLE IF EXISTS {{_pbl_audit_logs}}").Execute()
+
+		return err
+	})
+}
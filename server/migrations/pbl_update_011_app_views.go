@@ -0,0 +1,33 @@
+package migrations
+
+import (
+	"github.com/pocketbase/dbx"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		_, err := db.NewQuery(`
+		CREATE TABLE {{_pbl_app_views}} (
+			[[id]]            TEXT PRIMARY KEY NOT NULL,
+			[[app]]           TEXT NOT NULL,
+			[[viewer]]        TEXT DEFAULT "" NOT NULL,
+			[[mode]]          TEXT DEFAULT "" NOT NULL,
+			[[created]]       TEXT DEFAULT (strftime('%Y-%m-%d %H:%M:%fZ')) NOT NULL,
+			[[updated]]       TEXT DEFAULT (strftime('%Y-%m-%d %H:%M:%fZ')) NOT NULL,
+			---
+			FOREIGN KEY ([[app]]) REFERENCES {{_pbl_apps}} ([[id]]) ON UPDATE CASCADE ON DELETE CASCADE
+		);
+
+		CREATE INDEX _pbl_app_views_app_created_idx ON {{_pbl_app_views}} ([[app]], [[created]]);
+		CREATE INDEX _pbl_app_views_viewer_app_idx ON {{_pbl_app_views}} ([[viewer]], [[app]]);
+		CREATE INDEX _pbl_app_views_created_idx ON {{_pbl_app_views}} ([[created]]);
+		`).Execute()
+
+		return err
+	}, func(db dbx.Builder) error {
+		_, err := db.NewQuery("DROP TABLE IF EXISTS {{_pbl_app_views}}").Execute()
+
+		return err
+	})
+}
@@ -0,0 +1,32 @@
+package migrations
+
+import (
+	"github.com/pocketbase/dbx"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		_, err := db.NewQuery(`
+		CREATE TABLE {{_pbl_ai_threads}} (
+			[[id]]            TEXT PRIMARY KEY NOT NULL,
+			[[app]]           TEXT NOT NULL,
+			[[user]]          TEXT NOT NULL,
+			[[title]]         TEXT DEFAULT "" NOT NULL,
+			[[messages]]      JSON DEFAULT "[]" NOT NULL,
+			[[created]]       TEXT DEFAULT (strftime('%Y-%m-%d %H:%M:%fZ')) NOT NULL,
+			[[updated]]       TEXT DEFAULT (strftime('%Y-%m-%d %H:%M:%fZ')) NOT NULL,
+			---
+			FOREIGN KEY ([[app]]) REFERENCES {{_pbl_apps}} ([[id]]) ON UPDATE CASCADE ON DELETE CASCADE
+		);
+
+		CREATE INDEX _pbl_ai_threads_app_user_idx ON {{_pbl_ai_threads}} ([[app]], [[user]]);
+		`).Execute()
+
+		return err
+	}, func(db dbx.Builder) error {
+		_, err := db.NewQuery("DROP TABLE IF EXISTS {{_pbl_ai_threads}}").Execute()
+
+		return err
+	})
+}
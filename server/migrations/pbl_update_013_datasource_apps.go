@@ -0,0 +1,22 @@
+package migrations
+
+import (
+	"github.com/pocketbase/dbx"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		_, err := db.NewQuery(`
+		ALTER TABLE {{_pbl_datasources}} ADD COLUMN [[apps]] JSON DEFAULT "[]" NOT NULL;
+		`).Execute()
+
+		return err
+	}, func(db dbx.Builder) error {
+		_, err := db.NewQuery(`
+		ALTER TABLE {{_pbl_datasources}} DROP COLUMN [[apps]];
+		`).Execute()
+
+		return err
+	})
+}
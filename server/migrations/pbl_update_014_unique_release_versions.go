@@ -0,0 +1,21 @@
+package migrations
+
+import (
+	"github.com/pocketbase/dbx"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		// the rejected releases versions can be reused
+		_, err := db.NewQuery(`
+		CREATE UNIQUE INDEX _pbl_releases_app_version_idx ON {{_pbl_releases}} ([[app]], [[version]]) WHERE [[status]] != 'rejected';
+		`).Execute()
+
+		return err
+	}, func(db dbx.Builder) error {
+		_, err := db.NewQuery("DROP INDEX IF EXISTS _pbl_releases_app_version_idx").Execute()
+
+		return err
+	})
+}
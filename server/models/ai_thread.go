@@ -0,0 +1,60 @@
+package models
+
+import (
+	"encoding/json"
+
+	m "github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+var (
+	_ m.Model = (*AiThread)(nil)
+)
+
+// AiThreadMessage is a single turn of an AI conversation.
+//
+// The assistant turns only keep the generated explanation,
+// the generated dsls are applied to the app instead.
+type AiThreadMessage struct {
+	Role    string         `json:"role"`
+	Content string         `json:"content"`
+	Created types.DateTime `json:"created"`
+}
+
+// AiThread is an AI assistant conversation of a user about an application.
+type AiThread struct {
+	m.BaseModel
+
+	AppId       string             `db:"app" json:"app"`
+	UserId      string             `db:"user" json:"user"`
+	Title       string             `db:"title" json:"title"`
+	RawMessages string             `db:"messages" json:"-"`
+	Messages    []*AiThreadMessage `db:"-" json:"messages"`
+}
+
+func (m *AiThread) TableName() string {
+	return "_pbl_ai_threads"
+}
+
+func (m *AiThread) PostScan() error {
+	if err := m.BaseModel.PostScan(); err != nil {
+		return err
+	}
+
+	m.Messages = []*AiThreadMessage{}
+	if m.RawMessages != "" {
+		if err := json.Unmarshal([]byte(m.RawMessages), &m.Messages); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddMessage appends a new turn to the thread.
+func (m *AiThread) AddMessage(role string, content string) {
+	m.Messages = append(m.Messages, &AiThreadMessage{
+		Role:    role,
+		Content: content,
+		Created: types.NowDateTime(),
+	})
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	m "github.com/pocketbase/pocketbase/models"
+)
+
+var (
+	_ m.Model = (*AppView)(nil)
+)
+
+const (
+	// AppViewModeView is an app opened in the viewer (the published dsl).
+	AppViewModeView = "view"
+	// AppViewModeEdit is an app opened in the editor.
+	AppViewModeEdit = "edit"
+)
+
+// AppView is a single application view event.
+//
+// Viewer is the id of the user or admin that opened the app
+// (empty for the anonymous views of the public apps).
+type AppView struct {
+	m.BaseModel
+
+	AppId  string `db:"app" json:"app"`
+	Viewer string `db:"viewer" json:"viewer"`
+	Mode   string `db:"mode" json:"mode"`
+}
+
+func (m *AppView) TableName() string {
+	return "_pbl_app_views"
+}
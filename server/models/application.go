@@ -1,6 +1,10 @@
 package models
 
 import (
+	"encoding/json"
+	"slices"
+	"strings"
+
 	"github.com/guregu/null"
 	m "github.com/pocketbase/pocketbase/models"
 	"github.com/pocketbase/pocketbase/tools/list"
@@ -10,22 +14,50 @@ var (
 	_ m.Model = (*Application)(nil)
 )
 
+const (
+	AppTypeApplication = 1
+	AppTypeModule      = 2
+)
+
+const (
+	AppRoleViewer = "viewer"
+	AppRoleEditor = "editor"
+	// AppRoleReviewer is an editor that can also approve the app releases.
+	AppRoleReviewer = "reviewer"
+	AppRoleOwner    = "owner"
+)
+
+// AppRoles lists the application roles from the lowest to the highest one.
+var AppRoles = []string{AppRoleViewer, AppRoleEditor, AppRoleReviewer, AppRoleOwner}
+
+// AppRoleGreaterOrEqual checks whether role grants at least the same access as other.
+func AppRoleGreaterOrEqual(role string, other string) bool {
+	return slices.Index(AppRoles, role) >= slices.Index(AppRoles, other)
+}
+
 type Application struct {
 	m.BaseModel
 
-	Name      string      `db:"name" json:"name"`
-	Slug      string      `db:"slug" json:"slug"`
-	Type      int         `db:"type" json:"type"`
-	Status    string      `db:"status" json:"status"`
-	Public    bool        `db:"public" json:"public"`
-	AllUsers  bool        `db:"allUsers" json:"allUsers"`
-	RawGroups string      `db:"groups" json:"-"`
-	RawUsers  string      `db:"users" json:"-"`
-	Groups    []string    `db:"-" json:"groups"`
-	Users     []string    `db:"-" json:"users"`
-	AppDsl    string      `db:"appDSL" json:"appDSL"`
-	EditDsl   string      `db:"editDSL" json:"editDSL"`
-	FolderId  null.String `db:"folder" json:"folder"`
+	Name       string            `db:"name" json:"name"`
+	Slug       string            `db:"slug" json:"slug"`
+	Type       int               `db:"type" json:"type"`
+	Status     string            `db:"status" json:"status"`
+	Public     bool              `db:"public" json:"public"`
+	AllUsers   bool              `db:"allUsers" json:"allUsers"`
+	RawGroups  string            `db:"groups" json:"-"`
+	RawUsers   string            `db:"users" json:"-"`
+	RawRoles   string            `db:"roles" json:"-"`
+	Groups     []string          `db:"-" json:"groups"`
+	Users      []string          `db:"-" json:"users"`
+	Roles      map[string]string `db:"-" json:"roles"`
+	AppDsl     string            `db:"appDSL" json:"appDSL"`
+	EditDsl    string            `db:"editDSL" json:"editDSL"`
+	FolderId   null.String       `db:"folder" json:"folder"`
+	IsTemplate bool              `db:"isTemplate" json:"isTemplate"`
+	// TemplateId is the id of the template the app was created from.
+	TemplateId null.String `db:"templateId" json:"templateId"`
+	// ReleaseId is the id of the live release (the one AppDsl comes from).
+	ReleaseId string `db:"release" json:"release"`
 }
 
 func (m *Application) TableName() string {
@@ -39,5 +71,39 @@ func (m *Application) PostScan() error {
 
 	m.Groups = list.ToUniqueStringSlice(m.RawGroups)
 	m.Users = list.ToUniqueStringSlice(m.RawUsers)
+
+	m.Roles = map[string]string{}
+	if m.RawRoles != "" {
+		if err := json.Unmarshal([]byte(m.RawRoles), &m.Roles); err != nil {
+			return err
+		}
+	}
 	return nil
 }
+
+// MemberRole returns the role of the permission entry (eg. "USER_ID|USER"),
+// members without an explicit role are viewers.
+func (m *Application) MemberRole(permissionId string) string {
+	if role, ok := m.Roles[permissionId]; ok && role != "" {
+		return role
+	}
+	return AppRoleViewer
+}
+
+// HasMember checks whether the permission entry (eg. "USER_ID|USER",
+// "GROUP_ID|GROUP" or "all_users|GROUP") is a member of the application.
+func (m *Application) HasMember(permissionId string) bool {
+	if permissionId == "all_users|GROUP" {
+		return m.AllUsers
+	}
+
+	memberId, memberType, _ := strings.Cut(permissionId, "|")
+	switch memberType {
+	case "USER":
+		return slices.Contains(m.Users, memberId)
+	case "GROUP":
+		return slices.Contains(m.Groups, memberId)
+	default:
+		return false
+	}
+}
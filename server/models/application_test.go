@@ -0,0 +1,35 @@
+package models
+
+import "testing"
+
+func TestApplicationHasMember(t *testing.T) {
+	app := &Application{
+		Users:  []string{"user1"},
+		Groups: []string{"group1"},
+	}
+
+	scenarios := []struct {
+		permissionId string
+		expected     bool
+	}{
+		{"user1|USER", true},
+		{"user2|USER", false},
+		{"group1|GROUP", true},
+		{"group1|USER", false},
+		{"user1|GROUP", false},
+		{"all_users|GROUP", false},
+		{"user1", false},
+		{"", false},
+	}
+
+	for _, s := range scenarios {
+		if result := app.HasMember(s.permissionId); result != s.expected {
+			t.Errorf("[%s] Expected %v, got %v", s.permissionId, s.expected, result)
+		}
+	}
+
+	app.AllUsers = true
+	if !app.HasMember("all_users|GROUP") {
+		t.Error("Expected all_users to be a member")
+	}
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	m "github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+var (
+	_ m.Model = (*AuditLog)(nil)
+)
+
+const (
+	AuditActorAdmin = "admin"
+	AuditActorUser  = "user"
+)
+
+const (
+	AuditTargetApp          = "app"
+	AuditTargetFolder       = "folder"
+	AuditTargetDatasource   = "datasource"
+	AuditTargetLibraryQuery = "libraryQuery"
+	AuditTargetSettings     = "settings"
+)
+
+// AuditLog is a single administrative action recorded in the audit trail.
+//
+// Before and After hold small summaries of the target (never full dsls
+// or secrets) and are null when not relevant (eg. Before of a create).
+type AuditLog struct {
+	m.BaseModel
+
+	Actor      string        `db:"actor" json:"actor"`
+	ActorType  string        `db:"actorType" json:"actorType"`
+	ActorName  string        `db:"actorName" json:"actorName"`
+	Action     string        `db:"action" json:"action"`
+	TargetType string        `db:"targetType" json:"targetType"`
+	Target     string        `db:"target" json:"target"`
+	TargetName string        `db:"targetName" json:"targetName"`
+	Ip         string        `db:"ip" json:"ip"`
+	Before     types.JsonRaw `db:"before" json:"before"`
+	After      types.JsonRaw `db:"after" json:"after"`
+}
+
+func (m *AuditLog) TableName() string {
+	return "_pbl_audit_logs"
+}
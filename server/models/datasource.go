@@ -0,0 +1,132 @@
+package models
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+	m "github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/list"
+)
+
+var (
+	_ m.Model = (*Datasource)(nil)
+)
+
+const (
+	DatasourceTypeRestApi    = "restApi"
+	DatasourceTypePocketBase = "pocketbase"
+	DatasourceTypeSqlite     = "sqlite"
+)
+
+// DatasourceTypes lists every datasource type supported by PocketBlocks.
+var DatasourceTypes = []string{
+	DatasourceTypeRestApi,
+	DatasourceTypePocketBase,
+	DatasourceTypeSqlite,
+}
+
+type Datasource struct {
+	m.BaseModel
+
+	Name    string   `db:"name" json:"name"`
+	Type    string   `db:"type" json:"type"`
+	Config  string   `db:"config" json:"config"`
+	RawApps string   `db:"apps" json:"-"`
+	Apps    []string `db:"-" json:"apps"`
+}
+
+func (m *Datasource) TableName() string {
+	return "_pbl_datasources"
+}
+
+func (m *Datasource) PostScan() error {
+	if err := m.BaseModel.PostScan(); err != nil {
+		return err
+	}
+
+	m.Apps = list.ToUniqueStringSlice(m.RawApps)
+	return nil
+}
+
+// AllowsApp checks whether the queries of the provided application
+// are allowed to run against the datasource.
+func (m *Datasource) AllowsApp(appId string) bool {
+	return slices.Contains(m.Apps, appId)
+}
+
+// DecodeConfig unmarshals the raw datasource config into result.
+func (m *Datasource) DecodeConfig(result any) error {
+	if m.Config == "" {
+		return nil
+	}
+	return json.Unmarshal([]byte(m.Config), result)
+}
+
+// NewDatasourceConfig returns an empty config instance for the provided
+// datasource type (or nil if the type is unknown).
+func NewDatasourceConfig(datasourceType string) validation.Validatable {
+	switch datasourceType {
+	case DatasourceTypeRestApi:
+		return &RestApiConfig{}
+	case DatasourceTypePocketBase:
+		return &PocketBaseConfig{}
+	case DatasourceTypeSqlite:
+		return &SqliteConfig{}
+	default:
+		return nil
+	}
+}
+
+// KeyValue is a single key/value pair (eg. http header or query param).
+type KeyValue struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// RestApiConfig is the config of a REST API datasource.
+type RestApiConfig struct {
+	Url     string     `json:"url"`
+	Headers []KeyValue `json:"headers"`
+	Params  []KeyValue `json:"params"`
+}
+
+// Validate makes RestApiConfig validatable by implementing [validation.Validatable] interface.
+func (c *RestApiConfig) Validate() error {
+	return validation.ValidateStruct(c,
+		validation.Field(&c.Url, validation.Required, is.URL),
+	)
+}
+
+// PocketBaseConfig is the config of a PocketBase collection datasource.
+type PocketBaseConfig struct {
+	Collection string `json:"collection"`
+}
+
+// Validate makes PocketBaseConfig validatable by implementing [validation.Validatable] interface.
+func (c *PocketBaseConfig) Validate() error {
+	return validation.ValidateStruct(c,
+		validation.Field(&c.Collection, validation.Required),
+	)
+}
+
+// SqliteConfig is the config of a SQLite file datasource.
+type SqliteConfig struct {
+	Path     string `json:"path"`
+	ReadOnly bool   `json:"readOnly"`
+}
+
+// Validate makes SqliteConfig validatable by implementing [validation.Validatable] interface.
+func (c *SqliteConfig) Validate() error {
+	return validation.ValidateStruct(c,
+		validation.Field(&c.Path, validation.Required, validation.By(func(value any) error {
+			v, _ := value.(string)
+			if strings.Contains(v, "?") {
+				return validation.NewError("validation_invalid_path", "The path must not contain query parameters.")
+			}
+			return nil
+		})),
+	)
+}
@@ -1,7 +1,11 @@
 package models
 
 import (
+	"slices"
+
+	"github.com/guregu/null"
 	m "github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/list"
 )
 
 var (
@@ -11,9 +15,39 @@ var (
 type Folder struct {
 	m.BaseModel
 
-	Name string `db:"name" json:"name"`
+	Name      string      `db:"name" json:"name"`
+	ParentId  null.String `db:"parent" json:"parent"`
+	AllUsers  bool        `db:"allUsers" json:"allUsers"`
+	RawGroups string      `db:"groups" json:"-"`
+	RawUsers  string      `db:"users" json:"-"`
+	Groups    []string    `db:"-" json:"groups"`
+	Users     []string    `db:"-" json:"users"`
 }
 
 func (m *Folder) TableName() string {
 	return "_pbl_folders"
 }
+
+func (m *Folder) PostScan() error {
+	if err := m.BaseModel.PostScan(); err != nil {
+		return err
+	}
+
+	m.Groups = list.ToUniqueStringSlice(m.RawGroups)
+	m.Users = list.ToUniqueStringSlice(m.RawUsers)
+	return nil
+}
+
+// HasMember checks whether the folder itself grants access to the user
+// (directly, through one of the user groups or to all users).
+func (m *Folder) HasMember(userId string, groupIds []string) bool {
+	if m.AllUsers || slices.Contains(m.Users, userId) {
+		return true
+	}
+	for _, gId := range groupIds {
+		if slices.Contains(m.Groups, gId) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	m "github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+var (
+	_ m.Model = (*LibraryQuery)(nil)
+)
+
+// LibraryQuery is a query shared between applications.
+//
+// EditDsl holds the draft version and PublishedDsl the version used by
+// the applications (like [Application.EditDsl] and [Application.AppDsl]).
+type LibraryQuery struct {
+	m.BaseModel
+
+	Name          string         `db:"name" json:"name"`
+	EditDsl       string         `db:"editDSL" json:"editDSL"`
+	PublishedDsl  string         `db:"publishedDSL" json:"publishedDSL"`
+	Tag           string         `db:"tag" json:"tag"`
+	CommitMessage string         `db:"commitMessage" json:"commitMessage"`
+	Published     types.DateTime `db:"published" json:"published"`
+}
+
+func (m *LibraryQuery) TableName() string {
+	return "_pbl_library_queries"
+}
+
+// IsPublished checks whether the library query has a published version.
+func (m *LibraryQuery) IsPublished() bool {
+	return !m.Published.IsZero()
+}
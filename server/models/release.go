@@ -0,0 +1,48 @@
+package models
+
+import (
+	m "github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+var (
+	_ m.Model = (*Release)(nil)
+)
+
+const (
+	// ReleaseStatusPending is a release waiting for a reviewer approval.
+	ReleaseStatusPending = "pending"
+	// ReleaseStatusApproved is an approved release waiting for its scheduled time.
+	ReleaseStatusApproved = "approved"
+	// ReleaseStatusApplied is a release whose dsl went live (AppDsl).
+	ReleaseStatusApplied = "applied"
+	// ReleaseStatusRejected is a release refused by a reviewer.
+	ReleaseStatusRejected = "rejected"
+)
+
+// Release is a request to publish an application dsl.
+//
+// Its dsl, version and notes never change once created,
+// only the review/apply status does.
+type Release struct {
+	m.BaseModel
+
+	AppId       string         `db:"app" json:"app"`
+	Dsl         string         `db:"dsl" json:"dsl"`
+	Version     string         `db:"version" json:"version"`
+	Notes       string         `db:"notes" json:"notes"`
+	Status      string         `db:"status" json:"status"`
+	RequestedBy string         `db:"requestedBy" json:"requestedBy"`
+	ReviewedBy  string         `db:"reviewedBy" json:"reviewedBy"`
+	ScheduledAt types.DateTime `db:"scheduledAt" json:"scheduledAt"`
+	AppliedAt   types.DateTime `db:"appliedAt" json:"appliedAt"`
+}
+
+func (m *Release) TableName() string {
+	return "_pbl_releases"
+}
+
+// IsDue checks whether an approved release can go live at the provided time.
+func (m *Release) IsDue(now types.DateTime) bool {
+	return m.ScheduledAt.IsZero() || !m.ScheduledAt.Time().After(now.Time())
+}
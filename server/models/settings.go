@@ -30,6 +30,8 @@ type Settings struct {
 	Themes          string   `form:"themes" json:"themes"`
 	ThemeId         string   `form:"theme" json:"theme"`
 	Auths           Auths    `form:"auths" json:"auths"`
+
+	SnapshotRetention SnapshotRetention `form:"snapshotRetention" json:"snapshotRetention"`
 }
 
 // Validate is used by SettingsForm to validate fields
@@ -49,6 +51,7 @@ func (s *Settings) Validate(validateHomePageAppSlug ...validation.Rule) error {
 		validation.Field(&s.ThemeId, validation.Length(24, 24)),
 		validation.Field(&s.Auths),
 		validation.Field(&s.ShowTutorial, validation.Each(validation.Length(15, 15))),
+		validation.Field(&s.SnapshotRetention),
 	)
 }
 
@@ -136,6 +139,12 @@ func NewSettings() *Settings {
 	return &Settings{
 		Name:         "Acme Organization",
 		ShowTutorial: []string{},
+		SnapshotRetention: SnapshotRetention{
+			Cron:       "0 3 * * *",
+			KeepLast:   50,
+			DailyDays:  30,
+			WeeklyDays: 180,
+		},
 	}
 }
 
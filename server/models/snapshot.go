@@ -1,6 +1,11 @@
 package models
 
 import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"io"
+
 	m "github.com/pocketbase/pocketbase/models"
 )
 
@@ -8,14 +13,72 @@ var (
 	_ m.Model = (*Snapshot)(nil)
 )
 
+// SnapshotEncodingGzip marks a snapshot dsl stored as base64 encoded gzip data.
+const SnapshotEncodingGzip = "gzip"
+
 type Snapshot struct {
 	m.BaseModel
 
-	AppId   string `db:"app" json:"app"`
-	Dsl     string `db:"dsl" json:"dsl"`
-	Context string `db:"context" json:"context"`
+	AppId    string `db:"app" json:"app"`
+	Dsl      string `db:"dsl" json:"dsl"`
+	Context  string `db:"context" json:"context"`
+	Encoding string `db:"encoding" json:"-"`
 }
 
 func (m *Snapshot) TableName() string {
 	return "_pbl_app_snapshots"
 }
+
+// PostScan decodes the stored dsl, so Dsl always holds the plain JSON.
+func (m *Snapshot) PostScan() error {
+	if err := m.BaseModel.PostScan(); err != nil {
+		return err
+	}
+
+	if m.Encoding == SnapshotEncodingGzip {
+		dsl, err := DecompressSnapshotDsl(m.Dsl)
+		if err != nil {
+			return err
+		}
+		m.Dsl = dsl
+		m.Encoding = ""
+	}
+
+	return nil
+}
+
+// CompressSnapshotDsl gzips the provided dsl and returns it base64 encoded.
+func CompressSnapshotDsl(dsl string) (string, error) {
+	var buf bytes.Buffer
+
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write([]byte(dsl)); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// DecompressSnapshotDsl reverts [CompressSnapshotDsl].
+func DecompressSnapshotDsl(data string) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return "", err
+	}
+
+	r, err := gzip.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	dsl, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+
+	return string(dsl), nil
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/tools/cron"
+)
+
+// SnapshotRetention defines which app history snapshots are kept when pruning.
+//
+// The newest KeepLast snapshots of every app are always kept,
+// older ones are reduced to one per day for DailyDays
+// and to one per week for WeeklyDays, anything else is removed.
+type SnapshotRetention struct {
+	Enabled    bool   `form:"enabled" json:"enabled"`
+	Cron       string `form:"cron" json:"cron"`
+	KeepLast   int    `form:"keepLast" json:"keepLast"`
+	DailyDays  int    `form:"dailyDays" json:"dailyDays"`
+	WeeklyDays int    `form:"weeklyDays" json:"weeklyDays"`
+}
+
+// Validate makes SnapshotRetention validatable by implementing [validation.Validatable] interface.
+func (r SnapshotRetention) Validate() error {
+	return validation.ValidateStruct(&r,
+		validation.Field(&r.Cron, validation.When(r.Enabled, validation.Required), validation.By(checkCronExpression)),
+		// an all zero policy would prune the whole history
+		validation.Field(&r.KeepLast, validation.When(r.Enabled, validation.Required, validation.Min(1)).Else(validation.Min(0))),
+		validation.Field(&r.DailyDays, validation.Min(0)),
+		validation.Field(&r.WeeklyDays, validation.Min(0)),
+	)
+}
+
+func checkCronExpression(value any) error {
+	v, _ := value.(string)
+	if v == "" {
+		return nil
+	}
+
+	if _, err := cron.NewSchedule(v); err != nil {
+		return validation.NewError("validation_invalid_cron", err.Error())
+	}
+	return nil
+}
+
+// SelectExpired returns the snapshots (all of a single app) that
+// are not covered by the retention policy anymore.
+func (r SnapshotRetention) SelectExpired(snapshots []*Snapshot, now time.Time) []*Snapshot {
+	sorted := make([]*Snapshot, len(snapshots))
+	copy(sorted, snapshots)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Created.Time().After(sorted[j].Created.Time())
+	})
+
+	dailyLimit := now.AddDate(0, 0, -r.DailyDays)
+	weeklyLimit := now.AddDate(0, 0, -r.WeeklyDays)
+	keptDays := map[string]bool{}
+	keptWeeks := map[string]bool{}
+
+	expired := []*Snapshot{}
+	for i, s := range sorted {
+		created := s.Created.Time().UTC()
+		day := created.Format(time.DateOnly)
+		year, week := created.ISOWeek()
+		weekKey := fmt.Sprintf("%d-%02d", year, week)
+
+		switch {
+		case i < r.KeepLast:
+		case r.DailyDays > 0 && created.After(dailyLimit) && !keptDays[day]:
+		case r.WeeklyDays > 0 && created.After(weeklyLimit) && !keptWeeks[weekKey]:
+		default:
+			expired = append(expired, s)
+			continue
+		}
+
+		keptDays[day] = true
+		keptWeeks[weekKey] = true
+	}
+
+	return expired
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSnapshotRetentionValidate(t *testing.T) {
+	scenarios := []struct {
+		retention   SnapshotRetention
+		expectError bool
+	}{
+		{SnapshotRetention{}, false},
+		{SnapshotRetention{Enabled: true, Cron: "0 3 * * *"}, true},
+		{SnapshotRetention{Enabled: true, Cron: "0 3 * * *", DailyDays: 7, WeeklyDays: 30}, true},
+		{SnapshotRetention{Enabled: true, Cron: "0 3 * * *", KeepLast: 1}, false},
+		{SnapshotRetention{Enabled: true, KeepLast: 1}, true},
+		{SnapshotRetention{KeepLast: -1}, true},
+	}
+
+	for i, s := range scenarios {
+		err := s.retention.Validate()
+
+		if hasErr := err != nil; hasErr != s.expectError {
+			t.Errorf("[%d] Expected hasErr %v, got %v (%v)", i, s.expectError, hasErr, err)
+		}
+	}
+}
+
+func TestSnapshotRetentionSelectExpired(t *testing.T) {
+	now := time.Date(2024, 6, 30, 12, 0, 0, 0, time.UTC)
+
+	newSnapshot := func(id string, created time.Time) *Snapshot {
+		s := &Snapshot{}
+		s.Id = id
+		s.Created.Scan(created)
+		return s
+	}
+
+	snapshots := []*Snapshot{
+		newSnapshot("last1", now.Add(-1*time.Hour)),
+		newSnapshot("last2", now.Add(-2*time.Hour)),
+		newSnapshot("sameDay", now.Add(-3*time.Hour)),
+		newSnapshot("day1", now.AddDate(0, 0, -1)),
+		newSnapshot("day1Old", now.AddDate(0, 0, -1).Add(-time.Hour)),
+		newSnapshot("day5", now.AddDate(0, 0, -5)),
+		newSnapshot("week3", now.AddDate(0, 0, -21)),
+		newSnapshot("week3Old", now.AddDate(0, 0, -21).Add(-time.Hour)),
+		newSnapshot("tooOld", now.AddDate(0, 0, -60)),
+	}
+
+	scenarios := []struct {
+		retention SnapshotRetention
+		expected  []string
+	}{
+		{
+			SnapshotRetention{KeepLast: 2, DailyDays: 7, WeeklyDays: 30},
+			[]string{"sameDay", "day1Old", "week3Old", "tooOld"},
+		},
+		{
+			SnapshotRetention{KeepLast: 100},
+			[]string{},
+		},
+		{
+			SnapshotRetention{KeepLast: 1},
+			[]string{"last2", "sameDay", "day1", "day1Old", "day5", "week3", "week3Old", "tooOld"},
+		},
+	}
+
+	for i, s := range scenarios {
+		result := []string{}
+		for _, e := range s.retention.SelectExpired(snapshots, now) {
+			result = append(result, e.Id)
+		}
+		slices.Sort(result)
+		slices.Sort(s.expected)
+
+		if !slices.Equal(result, s.expected) {
+			t.Errorf("[%d] Expected %v, got %v", i, s.expected, result)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package utils
+
+import "slices"
+
+// FindModuleAppIds returns the unique ids of the module apps embedded
+// (at any depth) in the provided parsed DSL.
+func FindModuleAppIds(dsl any) []string {
+	result := []string{}
+	walkModules(dsl, &result)
+	return result
+}
+
+func walkModules(node any, result *[]string) {
+	switch v := node.(type) {
+	case map[string]any:
+		if v["compType"] == "module" {
+			if comp, ok := v["comp"].(map[string]any); ok {
+				if appId, ok := comp["appId"].(string); ok && appId != "" && !slices.Contains(*result, appId) {
+					*result = append(*result, appId)
+				}
+			}
+		}
+		for _, child := range v {
+			walkModules(child, result)
+		}
+	case []any:
+		for _, child := range v {
+			walkModules(child, result)
+		}
+	}
+}
+
+// CollectModuleDsls resolves the modules embedded in dsl (recursively)
+// with load and returns their dsl keyed by module app id.
+//
+// ancestors holds the ids of the apps being resolved, so a module embedding
+// one of its ancestors is skipped instead of looping forever.
+// Every module is loaded at most once (even if embedded by several modules)
+// and the ones load can't resolve (eg. missing or not allowed) are skipped.
+func CollectModuleDsls(dsl any, ancestors []string, load func(appId string) (any, bool)) map[string]any {
+	result := map[string]any{}
+
+	visited := map[string]bool{}
+	for _, appId := range ancestors {
+		visited[appId] = true
+	}
+
+	collectModuleDsls(dsl, visited, result, load)
+
+	return result
+}
+
+func collectModuleDsls(dsl any, visited map[string]bool, result map[string]any, load func(appId string) (any, bool)) {
+	for _, appId := range FindModuleAppIds(dsl) {
+		if visited[appId] {
+			continue
+		}
+		visited[appId] = true
+
+		moduleDsl, ok := load(appId)
+		if !ok {
+			continue
+		}
+		result[appId] = moduleDsl
+
+		collectModuleDsls(moduleDsl, visited, result, load)
+	}
+}
@@ -0,0 +1,278 @@
+package utils
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// DslDiff is the structural difference between two app DSLs.
+type DslDiff struct {
+	Components DslDiffSection `json:"components"`
+	Queries    DslDiffSection `json:"queries"`
+	Layout     DslDiffSection `json:"layout"`
+	Other      DslDiffSection `json:"other"`
+}
+
+// DslDiffSection holds the added, removed and changed entries of a DSL section.
+type DslDiffSection struct {
+	Added   []DslDiffEntry `json:"added"`
+	Removed []DslDiffEntry `json:"removed"`
+	Changed []DslDiffEntry `json:"changed"`
+}
+
+// DslDiffEntry is a single DSL entry (component, query, layout item or top level key).
+//
+// Paths lists the inner paths (eg. "comp.text") that differ for changed entries.
+type DslDiffEntry struct {
+	Key   string   `json:"key"`
+	Type  string   `json:"type,omitempty"`
+	From  any      `json:"from,omitempty"`
+	To    any      `json:"to,omitempty"`
+	Paths []string `json:"paths,omitempty"`
+}
+
+// IsEmpty checks whether the diff has no changes at all.
+func (d *DslDiff) IsEmpty() bool {
+	for _, s := range []DslDiffSection{d.Components, d.Queries, d.Layout, d.Other} {
+		if len(s.Added)+len(s.Removed)+len(s.Changed) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// DiffDsl compares two parsed app DSLs.
+//
+// Components are matched by name (nested components are compared on their own),
+// queries by name and layout entries by the name of the component they position.
+// Any other top level key (settings, tempStates, etc.) is compared as a whole.
+func DiffDsl(from, to any) *DslDiff {
+	fromMap, _ := from.(map[string]any)
+	toMap, _ := to.(map[string]any)
+
+	fromComps, fromLayout := collectDslUi(fromMap["ui"])
+	toComps, toLayout := collectDslUi(toMap["ui"])
+
+	return &DslDiff{
+		Components: diffEntries(fromComps, toComps),
+		Queries:    diffEntries(collectDslQueries(fromMap["queries"]), collectDslQueries(toMap["queries"])),
+		Layout:     diffEntries(fromLayout, toLayout),
+		Other:      diffEntries(collectDslOther(fromMap), collectDslOther(toMap)),
+	}
+}
+
+type dslEntry struct {
+	typ   string
+	value any
+}
+
+// isDslComponent checks whether the node looks like a named component ({compType, name, comp}).
+func isDslComponent(node map[string]any) bool {
+	_, hasType := node["compType"].(string)
+	_, hasName := node["name"].(string)
+	return hasType && hasName
+}
+
+func collectDslUi(ui any) (map[string]dslEntry, map[string]dslEntry) {
+	comps := map[string]dslEntry{}
+	layouts := map[string]map[string]any{}
+	idToName := map[string]string{}
+
+	var walk func(node any)
+	walk = func(node any) {
+		switch v := node.(type) {
+		case map[string]any:
+			if isDslComponent(v) {
+				name := v["name"].(string)
+				comps[name] = dslEntry{typ: v["compType"].(string), value: stripNestedComponents(v)}
+			}
+			if items, ok := v["items"].(map[string]any); ok {
+				for id, item := range items {
+					if m, ok := item.(map[string]any); ok && isDslComponent(m) {
+						idToName[id] = m["name"].(string)
+					}
+				}
+			}
+			switch layout := v["layout"].(type) {
+			case map[string]any:
+				for id, l := range layout {
+					if m, ok := l.(map[string]any); ok {
+						layouts[id] = m
+					}
+				}
+			case []any:
+				for _, l := range layout {
+					if m, ok := l.(map[string]any); ok {
+						if id, ok := m["i"].(string); ok {
+							layouts[id] = m
+						}
+					}
+				}
+			}
+			for key, child := range v {
+				if key != "layout" {
+					walk(child)
+				}
+			}
+		case []any:
+			for _, child := range v {
+				walk(child)
+			}
+		}
+	}
+	walk(ui)
+
+	layoutEntries := map[string]dslEntry{}
+	for id, l := range layouts {
+		key := id
+		if name, ok := idToName[id]; ok {
+			key = name
+		}
+		layoutEntries[key] = dslEntry{value: l}
+	}
+
+	return comps, layoutEntries
+}
+
+// stripNestedComponents returns a copy of the component without its layout
+// and with the nested components replaced by their names,
+// so that a child change is not reported on every ancestor.
+func stripNestedComponents(comp map[string]any) any {
+	var strip func(node any, root bool) any
+	strip = func(node any, root bool) any {
+		switch v := node.(type) {
+		case map[string]any:
+			if !root && isDslComponent(v) {
+				return "<" + v["name"].(string) + ">"
+			}
+			result := make(map[string]any, len(v))
+			for key, child := range v {
+				if key == "layout" {
+					continue
+				}
+				result[key] = strip(child, false)
+			}
+			return result
+		case []any:
+			result := make([]any, len(v))
+			for i, child := range v {
+				result[i] = strip(child, false)
+			}
+			return result
+		default:
+			return v
+		}
+	}
+	return strip(comp, true)
+}
+
+func collectDslQueries(queries any) map[string]dslEntry {
+	result := map[string]dslEntry{}
+
+	list, _ := queries.([]any)
+	for i, q := range list {
+		m, ok := q.(map[string]any)
+		if !ok {
+			continue
+		}
+		key, _ := m["name"].(string)
+		if key == "" {
+			key, _ = m["id"].(string)
+		}
+		if key == "" {
+			key = strconv.Itoa(i)
+		}
+		typ, _ := m["compType"].(string)
+		result[key] = dslEntry{typ: typ, value: m}
+	}
+
+	return result
+}
+
+func collectDslOther(dsl map[string]any) map[string]dslEntry {
+	result := map[string]dslEntry{}
+	for key, value := range dsl {
+		if key != "ui" && key != "queries" {
+			result[key] = dslEntry{value: value}
+		}
+	}
+	return result
+}
+
+func diffEntries(from, to map[string]dslEntry) DslDiffSection {
+	section := DslDiffSection{
+		Added:   []DslDiffEntry{},
+		Removed: []DslDiffEntry{},
+		Changed: []DslDiffEntry{},
+	}
+
+	for _, key := range sortedKeys(from) {
+		f := from[key]
+		t, ok := to[key]
+		if !ok {
+			section.Removed = append(section.Removed, DslDiffEntry{Key: key, Type: f.typ, From: f.value})
+			continue
+		}
+		if paths := diffPaths("", f.value, t.value); len(paths) > 0 {
+			section.Changed = append(section.Changed, DslDiffEntry{
+				Key:   key,
+				Type:  t.typ,
+				From:  f.value,
+				To:    t.value,
+				Paths: paths,
+			})
+		}
+	}
+
+	for _, key := range sortedKeys(to) {
+		if _, ok := from[key]; !ok {
+			t := to[key]
+			section.Added = append(section.Added, DslDiffEntry{Key: key, Type: t.typ, To: t.value})
+		}
+	}
+
+	return section
+}
+
+// diffPaths returns the (sorted) dot separated paths of the values that differ.
+func diffPaths(prefix string, from, to any) []string {
+	fromMap, fromOk := from.(map[string]any)
+	toMap, toOk := to.(map[string]any)
+	if !fromOk || !toOk {
+		if reflect.DeepEqual(from, to) {
+			return nil
+		}
+		if prefix == "" {
+			return []string{"."}
+		}
+		return []string{prefix}
+	}
+
+	keys := map[string]struct{}{}
+	for k := range fromMap {
+		keys[k] = struct{}{}
+	}
+	for k := range toMap {
+		keys[k] = struct{}{}
+	}
+
+	result := []string{}
+	for _, k := range sortedKeys(keys) {
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+		result = append(result, diffPaths(path, fromMap[k], toMap[k])...)
+	}
+	return result
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func parseTestDsl(t *testing.T, raw string) any {
+	var dsl any
+	if err := json.Unmarshal([]byte(raw), &dsl); err != nil {
+		t.Fatal(err)
+	}
+	return dsl
+}
+
+func TestDiffDsl(t *testing.T) {
+	from := parseTestDsl(t, `{
+		"ui": {"compType": "normal", "comp": {"container": {
+			"items": {
+				"a1": {"compType": "button", "name": "button1", "comp": {"text": "Ok"}},
+				"a2": {"compType": "text", "name": "text1", "comp": {"value": "Hi"}},
+				"a3": {"compType": "container", "name": "container1", "comp": {"container": {
+					"items": {"b1": {"compType": "input", "name": "input1", "comp": {"label": "A"}}},
+					"layout": {"b1": {"i": "b1", "x": 0, "y": 0, "w": 4, "h": 5}}
+				}}}
+			},
+			"layout": {
+				"a1": {"i": "a1", "x": 0, "y": 0, "w": 4, "h": 5},
+				"a2": {"i": "a2", "x": 4, "y": 0, "w": 4, "h": 5},
+				"a3": {"i": "a3", "x": 0, "y": 5, "w": 24, "h": 20}
+			}
+		}}},
+		"queries": [{"id": "q1", "name": "query1", "compType": "js", "comp": {"script": "return 1"}}],
+		"settings": {"title": "App"}
+	}`)
+	to := parseTestDsl(t, `{
+		"ui": {"compType": "normal", "comp": {"container": {
+			"items": {
+				"a1": {"compType": "button", "name": "button1", "comp": {"text": "Save"}},
+				"a3": {"compType": "container", "name": "container1", "comp": {"container": {
+					"items": {"b1": {"compType": "input", "name": "input1", "comp": {"label": "B"}}},
+					"layout": {"b1": {"i": "b1", "x": 0, "y": 0, "w": 4, "h": 5}}
+				}}},
+				"a4": {"compType": "table", "name": "table1", "comp": {}}
+			},
+			"layout": {
+				"a1": {"i": "a1", "x": 2, "y": 0, "w": 4, "h": 5},
+				"a3": {"i": "a3", "x": 0, "y": 5, "w": 24, "h": 20},
+				"a4": {"i": "a4", "x": 0, "y": 25, "w": 24, "h": 30}
+			}
+		}}},
+		"queries": [
+			{"id": "q1", "name": "query1", "compType": "js", "comp": {"script": "return 1"}},
+			{"id": "q2", "name": "query2", "compType": "restApi", "comp": {}}
+		],
+		"settings": {"title": "App"}
+	}`)
+
+	diff := DiffDsl(from, to)
+
+	keys := func(entries []DslDiffEntry) []string {
+		result := []string{}
+		for _, e := range entries {
+			result = append(result, e.Key)
+		}
+		return result
+	}
+
+	scenarios := []struct {
+		name     string
+		result   []string
+		expected []string
+	}{
+		{"components added", keys(diff.Components.Added), []string{"table1"}},
+		{"components removed", keys(diff.Components.Removed), []string{"text1"}},
+		// container1 is not reported since only its child changed
+		{"components changed", keys(diff.Components.Changed), []string{"button1", "input1"}},
+		{"queries added", keys(diff.Queries.Added), []string{"query2"}},
+		{"queries changed", keys(diff.Queries.Changed), []string{}},
+		{"layout added", keys(diff.Layout.Added), []string{"table1"}},
+		{"layout removed", keys(diff.Layout.Removed), []string{"text1"}},
+		{"layout changed", keys(diff.Layout.Changed), []string{"button1"}},
+		{"button1 paths", diff.Components.Changed[0].Paths, []string{"comp.text"}},
+		{"other changed", keys(diff.Other.Changed), []string{}},
+	}
+
+	for _, s := range scenarios {
+		if !slices.Equal(s.result, s.expected) {
+			t.Errorf("[%s] Expected %v, got %v", s.name, s.expected, s.result)
+		}
+	}
+
+	if diff.IsEmpty() {
+		t.Fatal("Expected a non empty diff")
+	}
+	if !DiffDsl(from, from).IsEmpty() {
+		t.Fatal("Expected an empty diff when comparing the same DSL")
+	}
+}
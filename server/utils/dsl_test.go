@@ -0,0 +1,101 @@
+package utils
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestFindModuleAppIds(t *testing.T) {
+	dsl := parseTestDsl(t, `{
+		"ui": {"compType": "normal", "comp": {"container": {"items": {
+			"a1": {"compType": "module", "comp": {"appId": "mod1"}},
+			"a2": {"compType": "container", "comp": {"container": {"items": {
+				"b1": {"compType": "module", "comp": {"appId": "mod2"}},
+				"b2": {"compType": "module", "comp": {"appId": "mod1"}},
+				"b3": {"compType": "module", "comp": {"appId": ""}},
+				"b4": {"compType": "module", "comp": {}}
+			}}}},
+			"a3": {"compType": "listView", "comp": {"items": [
+				{"compType": "module", "comp": {"appId": "mod3"}}
+			]}},
+			"a4": {"compType": "button", "comp": {"appId": "notAModule"}}
+		}}}}
+	}`)
+
+	result := FindModuleAppIds(dsl)
+	slices.Sort(result)
+
+	expected := []string{"mod1", "mod2", "mod3"}
+	if !slices.Equal(result, expected) {
+		t.Fatalf("Expected %v, got %v", expected, result)
+	}
+
+	if result := FindModuleAppIds(nil); len(result) != 0 {
+		t.Fatalf("Expected no module ids, got %v", result)
+	}
+}
+
+func TestCollectModuleDsls(t *testing.T) {
+	moduleDsl := func(appIds ...string) any {
+		items := map[string]any{}
+		for _, id := range appIds {
+			items[id] = map[string]any{"compType": "module", "comp": map[string]any{"appId": id}}
+		}
+		return map[string]any{"ui": map[string]any{"items": items}}
+	}
+
+	modules := map[string]any{
+		"nested1": moduleDsl("nested2"),
+		"nested2": moduleDsl("nested3"),
+		"nested3": moduleDsl(),
+		"cycle1":  moduleDsl("cycle2"),
+		"cycle2":  moduleDsl("cycle1"),
+		"self":    moduleDsl("self"),
+		"toRoot":  moduleDsl("root"),
+		"broken":  moduleDsl("missing"),
+		"diamond": moduleDsl("left", "right"),
+		"left":    moduleDsl("shared"),
+		"right":   moduleDsl("shared"),
+		"shared":  moduleDsl("nested3"),
+	}
+
+	scenarios := []struct {
+		name     string
+		dsl      any
+		expected []string
+		loads    int
+	}{
+		{"no modules", moduleDsl(), []string{}, 0},
+		{"nested modules", moduleDsl("nested1"), []string{"nested1", "nested2", "nested3"}, 3},
+		{"cyclic modules", moduleDsl("cycle1"), []string{"cycle1", "cycle2"}, 2},
+		{"self embedding module", moduleDsl("self"), []string{"self"}, 1},
+		{"module embedding the root app", moduleDsl("toRoot", "root"), []string{"toRoot"}, 1},
+		{"missing modules", moduleDsl("broken", "missing"), []string{"broken"}, 2},
+		{"shared modules", moduleDsl("diamond", "shared"), []string{"diamond", "left", "nested3", "right", "shared"}, 5},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			loads := 0
+
+			result := CollectModuleDsls(s.dsl, []string{"root"}, func(appId string) (any, bool) {
+				loads++
+				dsl, ok := modules[appId]
+				return dsl, ok
+			})
+
+			if keys := slices.Sorted(maps.Keys(result)); !slices.Equal(keys, s.expected) {
+				t.Fatalf("Expected modules %v, got %v", s.expected, keys)
+			}
+			for _, id := range s.expected {
+				if result[id] == nil {
+					t.Fatalf("Missing the %q module dsl", id)
+				}
+			}
+			if loads != s.loads {
+				t.Fatalf("Expected %d loads, got %d", s.loads, loads)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package utils
+
+import "testing"
+
+func TestParseSemver(t *testing.T) {
+	scenarios := []struct {
+		version string
+		valid   bool
+	}{
+		{"1.0.0", true},
+		{"0.10.3", true},
+		{"1.0.0-beta.1+build.5", true},
+		{"1.0", false},
+		{"01.0.0", false},
+		{"v1.0.0", false},
+		{"1.0.0-", false},
+	}
+
+	for _, s := range scenarios {
+		v, err := ParseSemver(s.version)
+		if (err == nil) != s.valid {
+			t.Errorf("[%s] expected valid %v, got error %v", s.version, s.valid, err)
+			continue
+		}
+		if s.valid && v.String() != s.version {
+			t.Errorf("[%s] expected the same string, got %s", s.version, v.String())
+		}
+	}
+}
+
+func TestSemverCompare(t *testing.T) {
+	// ordered by precedence (https://semver.org/#spec-item-11)
+	ordered := []string{
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"1.0.1",
+		"1.2.0",
+		"2.0.0",
+	}
+
+	for i := 1; i < len(ordered); i++ {
+		a, _ := ParseSemver(ordered[i-1])
+		b, _ := ParseSemver(ordered[i])
+		if a.Compare(b) != -1 || b.Compare(a) != 1 {
+			t.Errorf("expected %s < %s", ordered[i-1], ordered[i])
+		}
+	}
+
+	a, _ := ParseSemver("1.0.0+build.1")
+	b, _ := ParseSemver("1.0.0+build.2")
+	if a.Compare(b) != 0 {
+		t.Errorf("expected the build metadata to be ignored")
+	}
+}
+
+func TestSemverNextPatch(t *testing.T) {
+	scenarios := map[string]string{
+		"1.0.0":        "1.0.1",
+		"1.2.9":        "1.2.10",
+		"2.0.0-rc.1":   "2.0.0",
+		"1.0.0+build1": "1.0.1",
+	}
+
+	for version, expected := range scenarios {
+		v, _ := ParseSemver(version)
+		if next := v.NextPatch().String(); next != expected {
+			t.Errorf("[%s] expected %s, got %s", version, expected, next)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package workspace
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/fatih/color"
+	"github.com/pedrozadotdev/pocketblocks/server/daos"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/spf13/cobra"
+)
+
+// Config defines the config options of the workspace commands.
+type Config struct {
+	// PublicDir is the directory served at <host>/pbl/*
+	// (its files are included in the backup).
+	PublicDir string
+}
+
+// MustRegister registers the "workspace backup|restore" commands to the
+// provided app instance and panic if it fails.
+//
+// Example usage:
+//
+//	workspace.MustRegister(app, app.RootCmd, workspace.Config{PublicDir: publicDir})
+func MustRegister(app core.App, rootCmd *cobra.Command, config Config) {
+	if err := Register(app, rootCmd, config); err != nil {
+		panic(err)
+	}
+}
+
+// Register registers the "workspace backup|restore" commands to the provided app instance.
+func Register(app core.App, rootCmd *cobra.Command, config Config) error {
+	command := &cobra.Command{
+		Use:   "workspace",
+		Short: "Backups and restores the whole PocketBlocks workspace",
+	}
+
+	command.AddCommand(backupCmd(app, config), restoreCmd(app, config))
+	rootCmd.AddCommand(command)
+
+	return nil
+}
+
+func backupCmd(app core.App, config Config) *cobra.Command {
+	return &cobra.Command{
+		Use:          "backup [file]",
+		Short:        "Backups the apps, folders, snapshots, settings, groups and public dir files into a zip archive",
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(command *cobra.Command, args []string) error {
+			output := "pbl_workspace_" + time.Now().UTC().Format("20060102150405") + ".zip"
+			if len(args) > 0 {
+				output = args[0]
+			}
+
+			f, err := os.Create(output)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			manifest, err := Backup(daos.New(app.Dao().DB()), config.PublicDir, f)
+			if err != nil {
+				os.Remove(output)
+				return err
+			}
+
+			color.Green("Successfully created the workspace backup %s", output)
+			printManifest(manifest)
+			return nil
+		},
+	}
+}
+
+func restoreCmd(app core.App, config Config) *cobra.Command {
+	var force bool
+
+	command := &cobra.Command{
+		Use:          "restore [file]",
+		Short:        "Replaces the current workspace with the one from a backup archive",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(command *cobra.Command, args []string) error {
+			f, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			info, err := f.Stat()
+			if err != nil {
+				return err
+			}
+
+			if !force {
+				confirm := false
+				prompt := &survey.Confirm{
+					Message: "The current apps, folders, snapshots, settings and groups will be replaced. Do you want to proceed?",
+				}
+				survey.AskOne(prompt, &confirm)
+				if !confirm {
+					fmt.Println("The command has been cancelled.")
+					return nil
+				}
+			}
+
+			manifest, err := Restore(daos.New(app.Dao().DB()), config.PublicDir, f, info.Size())
+			if err != nil {
+				return err
+			}
+
+			color.Green("Successfully restored the workspace backup from %s", manifest.CreatedAt)
+			printManifest(manifest)
+			return nil
+		},
+	}
+
+	command.Flags().BoolVar(&force, "force", false, "skip the confirmation prompt")
+
+	return command
+}
+
+func printManifest(manifest *Manifest) {
+	for _, table := range Tables {
+		fmt.Printf("%s: %d\n", table, manifest.Tables[table])
+	}
+	fmt.Printf("public files: %d\n", manifest.Files)
+}
@@ -0,0 +1,342 @@
+// Package workspace implements the backup and restore of a whole
+// PocketBlocks workspace (the PocketBlocks tables, settings, groups
+// and public dir files) as a single zip archive.
+//
+// Example usage:
+//
+//	workspace.MustRegister(app, app.RootCmd, workspace.Config{PublicDir: publicDir})
+package workspace
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pedrozadotdev/pocketblocks/server/daos"
+	"github.com/pedrozadotdev/pocketblocks/server/models"
+	"github.com/pocketbase/dbx"
+	pbDaos "github.com/pocketbase/pocketbase/daos"
+)
+
+// Version is the current archive format version.
+const Version = 1
+
+const (
+	manifestName = "manifest.json"
+	settingsName = "settings.json"
+	tablesDir    = "tables/"
+	publicDir    = "public/"
+)
+
+// Tables lists the backed up tables in their restore order
+// (parents first, so the foreign keys are satisfied).
+var Tables = []string{
+	"_pbl_folders",
+	"_pbl_apps",
+	"_pbl_app_snapshots",
+	"_pbl_releases",
+	"_pbl_app_views",
+	"_pbl_ai_threads",
+	"_pbl_datasources",
+	"_pbl_library_queries",
+	"_pbl_audit_logs",
+	"groups",
+}
+
+// Manifest describes the content of a workspace archive.
+type Manifest struct {
+	Version   int            `json:"version"`
+	CreatedAt string         `json:"createdAt"`
+	Tables    map[string]int `json:"tables"`
+	Files     int            `json:"files"`
+}
+
+// Row is a raw table row (NULL values are nil).
+type Row map[string]*string
+
+// Backup writes the workspace archive into w.
+func Backup(dao *daos.Dao, publicDirPath string, w io.Writer) (*Manifest, error) {
+	manifest := &Manifest{
+		Version:   Version,
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+		Tables:    map[string]int{},
+	}
+
+	zw := zip.NewWriter(w)
+
+	for _, table := range Tables {
+		rows, err := readTable(dao, table)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", table, err)
+		}
+		if err := writeZipJson(zw, tablesDir+table+".json", rows); err != nil {
+			return nil, err
+		}
+		manifest.Tables[table] = len(rows)
+	}
+
+	settings, err := dao.FindParamByKey(models.ParamPblSettings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the settings: %w", err)
+	}
+	if err := writeZipJson(zw, settingsName, json.RawMessage(settings.Value)); err != nil {
+		return nil, err
+	}
+
+	if publicDirPath != "" {
+		if manifest.Files, err = addPublicDir(zw, publicDirPath); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writeZipJson(zw, manifestName, manifest); err != nil {
+		return nil, err
+	}
+
+	return manifest, zw.Close()
+}
+
+func readTable(dao *daos.Dao, table string) ([]Row, error) {
+	raw := []dbx.NullStringMap{}
+	if err := dao.DB().Select("*").From(table).OrderBy("created ASC").All(&raw); err != nil {
+		return nil, err
+	}
+
+	rows := make([]Row, 0, len(raw))
+	for _, r := range raw {
+		row := make(Row, len(r))
+		for column, value := range r {
+			if value.Valid {
+				v := value.String
+				row[column] = &v
+			} else {
+				row[column] = nil
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func writeZipJson(zw *zip.Writer, name string, data any) error {
+	f, err := zw.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	return json.NewEncoder(f).Encode(data)
+}
+
+func addPublicDir(zw *zip.Writer, root string) (int, error) {
+	total := 0
+
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && p == root {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+
+		src, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		header.Name = publicDir + filepath.ToSlash(rel)
+		header.Method = zip.Deflate
+
+		dst, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(dst, src); err != nil {
+			return err
+		}
+
+		total++
+		return nil
+	})
+
+	return total, err
+}
+
+// Restore replaces the current workspace with the one from the provided
+// archive (the archive public dir files are copied into publicDirPath,
+// overwriting the existing ones).
+//
+// The database is expected to have all migrations applied.
+func Restore(dao *daos.Dao, publicDirPath string, r io.ReaderAt, size int64) (*Manifest, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &Manifest{}
+	if err := readZipJson(zr, manifestName, manifest); err != nil {
+		return nil, fmt.Errorf("invalid workspace archive: %w", err)
+	}
+	if manifest.Version > Version {
+		return nil, fmt.Errorf("unsupported workspace archive version %d", manifest.Version)
+	}
+
+	var settings json.RawMessage
+	if err := readZipJson(zr, settingsName, &settings); err != nil {
+		return nil, fmt.Errorf("invalid workspace archive: %w", err)
+	}
+
+	txErr := dao.RunInTransaction(func(txDao *pbDaos.Dao) error {
+		// the foreign keys are checked only on commit, after all rows are inserted
+		if _, err := txDao.DB().NewQuery("PRAGMA defer_foreign_keys = ON").Execute(); err != nil {
+			return err
+		}
+
+		// children first
+		for _, table := range slices.Backward(Tables) {
+			if _, err := txDao.DB().Delete(table, nil).Execute(); err != nil {
+				return fmt.Errorf("failed to clear %s: %w", table, err)
+			}
+		}
+
+		for _, table := range Tables {
+			// table added after the archive was created
+			if _, ok := manifest.Tables[table]; !ok {
+				continue
+			}
+
+			rows := []Row{}
+			if err := readZipJson(zr, tablesDir+table+".json", &rows); err != nil {
+				return fmt.Errorf("invalid workspace archive: %w", err)
+			}
+			if err := insertRows(txDao, table, rows); err != nil {
+				return fmt.Errorf("failed to restore %s: %w", table, err)
+			}
+		}
+
+		return txDao.SaveParam(models.ParamPblSettings, settings)
+	})
+	if txErr != nil {
+		return nil, txErr
+	}
+
+	if err := dao.RefreshPblSettings(); err != nil {
+		return nil, err
+	}
+
+	if publicDirPath != "" {
+		if err := extractPublicDir(zr, publicDirPath); err != nil {
+			return nil, err
+		}
+	}
+
+	return manifest, nil
+}
+
+func readZipJson(zr *zip.Reader, name string, result any) error {
+	f, err := zr.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return json.NewDecoder(f).Decode(result)
+}
+
+// insertRows inserts the rows into the table, skipping the columns
+// that don't exist anymore (eg. archives from an older version).
+func insertRows(dao *pbDaos.Dao, table string, rows []Row) error {
+	columns, err := dao.TableColumns(table)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		params := dbx.Params{}
+		for column, value := range row {
+			if !slices.Contains(columns, column) {
+				continue
+			}
+			if value == nil {
+				params[column] = nil
+			} else {
+				params[column] = *value
+			}
+		}
+
+		if _, err := dao.DB().Insert(table, params).Execute(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func extractPublicDir(zr *zip.Reader, root string) error {
+	for _, file := range zr.File {
+		if !strings.HasPrefix(file.Name, publicDir) || strings.HasSuffix(file.Name, "/") {
+			continue
+		}
+
+		rel := path.Clean(strings.TrimPrefix(file.Name, publicDir))
+		if !filepath.IsLocal(rel) {
+			continue
+		}
+
+		if err := extractFile(file, filepath.Join(root, filepath.FromSlash(rel))); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func extractFile(file *zip.File, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+		return err
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	f, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(f, src)
+	return err
+}